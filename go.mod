@@ -5,6 +5,7 @@ go 1.22.7
 require (
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/sirupsen/logrus v1.9.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require golang.org/x/sys v0.8.0 // indirect
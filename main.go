@@ -6,6 +6,10 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/drone/drone-artifactory/plugin"
 
 	"github.com/kelseyhightower/envconfig"
@@ -15,6 +19,10 @@ import (
 func main() {
 	logrus.SetFormatter(new(formatter))
 
+	if err := plugin.CheckFlagMaps(); err != nil {
+		logrus.Fatalln(err)
+	}
+
 	var args plugin.Args
 	if err := envconfig.Process("", &args); err != nil {
 		logrus.Fatalln(err)
@@ -29,7 +37,12 @@ func main() {
 		logrus.SetLevel(logrus.TraceLevel)
 	}
 
-	if err := plugin.Exec(context.Background(), args); err != nil {
+	// cancel the running commands when the runner stops the step
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	if err := plugin.Exec(ctx, args); err != nil {
+		stop()
 		logrus.Fatalln(err)
 	}
 }
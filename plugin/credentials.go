@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// secretEnvVars lists the settings holding secrets, they are not passed on to
+// the commands run by the step.
+var secretEnvVars = []string{
+	"PLUGIN_PASSWORD",
+	"PLUGIN_API_KEY",
+	"PLUGIN_ACCESS_TOKEN",
+	"PLUGIN_PEM_FILE_CONTENTS",
+	"PLUGIN_PROXY_PASSWORD",
+}
+
+// withCredentialFiles returns args with the secrets read from the files of
+// PLUGIN_PASSWORD_FILE, PLUGIN_API_KEY_FILE, PLUGIN_ACCESS_TOKEN_FILE,
+// PLUGIN_PEM_FILE and PLUGIN_PROXY_PASSWORD_FILE, such as mounted Kubernetes
+// secrets or Vault agent sinks.
+func withCredentialFiles(args Args) (Args, error) {
+	var problems []string
+	for _, secret := range []struct {
+		setting, fileSetting string
+		value                *string
+		file                 string
+		trim                 bool
+	}{
+		{"PLUGIN_PASSWORD", "PLUGIN_PASSWORD_FILE", &args.Password, args.PasswordFile, true},
+		{"PLUGIN_API_KEY", "PLUGIN_API_KEY_FILE", &args.APIKey, args.APIKeyFile, true},
+		{"PLUGIN_ACCESS_TOKEN", "PLUGIN_ACCESS_TOKEN_FILE", &args.AccessToken, args.AccessTokenFile, true},
+		{"PLUGIN_PEM_FILE_CONTENTS", "PLUGIN_PEM_FILE", &args.PEMFileContents, args.PEMFile, false},
+		{"PLUGIN_PROXY_PASSWORD", "PLUGIN_PROXY_PASSWORD_FILE", &args.ProxyPassword, args.ProxyPasswordFile, true},
+	} {
+		if secret.file == "" {
+			continue
+		}
+		if *secret.value != "" {
+			problems = append(problems, fmt.Sprintf("%s and %s cannot be set together", secret.setting, secret.fileSetting))
+			continue
+		}
+		data, err := os.ReadFile(secret.file)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", secret.fileSetting, err))
+			continue
+		}
+		value := string(data)
+		if secret.trim {
+			// files written by editors and secret stores end with a newline
+			value = strings.TrimSpace(value)
+		}
+		if strings.TrimSpace(value) == "" {
+			problems = append(problems, fmt.Sprintf("%s: file %s is empty", secret.fileSetting, secret.file))
+			continue
+		}
+		*secret.value = value
+	}
+	if len(problems) > 0 {
+		return args, &SettingsError{Command: "credentials", Problems: problems}
+	}
+	return args, nil
+}
+
+// childEnv returns the environment of the commands run by the step, without
+// the secret settings nor any variable holding one of the secrets of args.
+func childEnv(args Args) []string {
+	redactor := newRedactor(args)
+	secrets := map[string]bool{}
+	for _, secret := range redactor.secrets {
+		secrets[secret] = true
+	}
+	excluded := map[string]bool{}
+	for _, name := range secretEnvVars {
+		excluded[name] = true
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if excluded[name] || secrets[value] {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithCredentialFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	pem := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+	args, err := withCredentialFiles(Args{
+		Username:        "ab",
+		PasswordFile:    write("password", "cd3f\n"),
+		AccessTokenFile: write("token", RtAccessToken),
+		PEMFile:         write("cert.pem", pem),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if args.Password != "cd3f" || args.AccessToken != RtAccessToken || args.PEMFileContents != pem {
+		t.Errorf("Unexpected credentials: %q %q %q", args.Password, args.AccessToken, args.PEMFileContents)
+	}
+
+	cmdArgs, err := setAuthParams([]string{}, args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(cmdArgs, " "); got != "--user=ab --password-stdin" {
+		t.Errorf("Unexpected auth params: %s", got)
+	}
+}
+
+func TestWithCredentialFilesProblems(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := withCredentialFiles(Args{
+		Password:     "cd3f",
+		PasswordFile: empty,
+		APIKeyFile:   filepath.Join(dir, "missing"),
+		PEMFile:      empty,
+	})
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	for _, want := range []string{
+		"invalid settings for credentials: PLUGIN_PASSWORD and PLUGIN_PASSWORD_FILE cannot be set together",
+		"PLUGIN_API_KEY_FILE: open " + filepath.Join(dir, "missing"),
+		"PLUGIN_PEM_FILE: file " + empty + " is empty",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in: %s", want, err)
+		}
+	}
+}
+
+func TestChildEnv(t *testing.T) {
+	t.Setenv("PLUGIN_ACCESS_TOKEN", RtAccessToken)
+	t.Setenv("PLUGIN_URL", RtUrlTestStr)
+	t.Setenv("EU_TOKEN", "eu-s3cr3t")
+
+	env := strings.Join(childEnv(Args{
+		AccessToken: RtAccessToken,
+		Servers:     `[{"url": "https://eu.example.com/artifactory/", "access_token_env": "EU_TOKEN"}]`,
+	}), "\n")
+	if strings.Contains(env, "PLUGIN_ACCESS_TOKEN=") || strings.Contains(env, "EU_TOKEN=") {
+		t.Errorf("Expected the secrets to be removed, Got: %s", env)
+	}
+	if !strings.Contains(env, "PLUGIN_URL="+RtUrlTestStr) {
+		t.Errorf("Expected PLUGIN_URL to be kept, Got: %s", env)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package plugin
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// killProcessTreeOnCancel makes cancelling cmd also kill the processes jf
+// spawns, like maven or gradle.
+func killProcessTreeOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+	}
+}
@@ -1,12 +1,33 @@
 package plugin
 
 import (
-	"fmt"
 	"runtime"
 
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	RegisterRtCommand(GradleCmd, defaultBuildToolCommand, rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_TASKS"},
+			flagMaps: [][]JsonTagToExeFlagMapStringItem{GradleConfigJsonTagToExeFlagMapStringItemList,
+				GradleRunJsonTagToExeFlagMapStringItemList},
+		},
+		commands: GetGradleCommandArgs,
+	})
+	RegisterRtCommand(GradleCmd, Publish, rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+			flagMaps: [][]JsonTagToExeFlagMapStringItem{GradleConfigCmdJsonTagToExeFlagMapStringItemList,
+				RtBuildInfoPublishCmdJsonTagToExeFlagMap, BuildDiscardCmdJsonTagToExeFlagMapStringItemList},
+		},
+		commands:     GetGradlePublishCommand,
+		postCommands: GetDeployerPublishPostCommands,
+	})
+}
+
 var GradleConfigJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
 	{"--deploy-ivy-desc=", "PLUGIN_DEPLOY_IVY_DESC", false, false},
 	{"--deploy-maven-desc=", "PLUGIN_DEPLOY_MAVEN_DESC", false, false},
@@ -35,7 +56,7 @@ func GetGradleCommandArgs(args Args) ([][]string, error) {
 
 	var cmdList [][]string
 
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(args.ResolverId,
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(resolverServerId(args),
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
@@ -45,11 +66,17 @@ func GetGradleCommandArgs(args Args) ([][]string, error) {
 	// Add necessary parameters for Windows to prevent all interactive prompts
 	if runtime.GOOS == "windows" {
 		// These parameters prevent all interactive prompts
-		gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--global=true")
+		if args.Global == "" {
+			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--global=true")
+		}
 		// Add server ID for deployment/resolution
 		if args.ResolverId != "" {
-			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-resolve="+args.ResolverId)
-			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-deploy="+args.ResolverId)
+			if args.ServerIdResolve == "" {
+				gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-resolve="+args.ResolverId)
+			}
+			if args.ServerIdDeploy == "" {
+				gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-deploy="+args.ResolverId)
+			}
 		}
 		// Add repos to prevent prompts
 		if args.RepoResolve == "" {
@@ -59,7 +86,9 @@ func GetGradleCommandArgs(args Args) ([][]string, error) {
 			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--repo-deploy=libs-release-local")
 		}
 		// Use maven-style plugin to enable dependency resolution
-		gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--uses-plugin=true")
+		if args.UsesPlugin == "" {
+			gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--uses-plugin=true")
+		}
 	}
 
 	err = PopulateArgs(&gradleConfigCommandArgs, &args, GradleConfigJsonTagToExeFlagMapStringItemList)
@@ -67,14 +96,14 @@ func GetGradleCommandArgs(args Args) ([][]string, error) {
 		return cmdList, err
 	}
 
-	gradleTaskCommandArgs := []string{GradleCmd, args.GradleTasks}
+	gradleTaskCommandArgs := append([]string{GradleCmd}, splitCommandLine(args.GradleTasks)...)
 	err = PopulateArgs(&gradleTaskCommandArgs, &args, GradleRunJsonTagToExeFlagMapStringItemList)
 	if err != nil {
 		return cmdList, err
 	}
 
 	if len(args.BuildFile) > 0 {
-		gradleTaskCommandArgs = append(gradleTaskCommandArgs, "-b "+args.BuildFile)
+		gradleTaskCommandArgs = append(gradleTaskCommandArgs, "-b", args.BuildFile)
 	}
 
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
@@ -104,8 +133,10 @@ func GetGradlePublishCommand(args Args) ([][]string, error) {
 	var cmdList [][]string
 	var jfrogConfigAddConfigCommandArgs []string
 
-	tmpServerId := args.DeployerId
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
+	// the server config holds the credentials, whichever the kind, so they
+	// are never passed to gradle
+	serverId := deployerServerId(args)
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
@@ -118,44 +149,19 @@ func GetGradlePublishCommand(args Args) ([][]string, error) {
 		logrus.Println("PopulateArgs error: ", err)
 		return cmdList, err
 	}
-	gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-deploy="+tmpServerId)
-	gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-resolve="+tmpServerId)
+	if args.ServerIdDeploy == "" {
+		gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-deploy="+serverId)
+	}
+	if args.ResolverId == "" {
+		gradleConfigCommandArgs = append(gradleConfigCommandArgs, "--server-id-resolve="+serverId)
+	}
 
 	rtPublishCommandArgs := []string{"gradle", Publish}
-	switch {
-	case args.Username != "":
-		rtPublishCommandArgs = append(rtPublishCommandArgs, "-Pusername="+args.Username)
-		rtPublishCommandArgs = append(rtPublishCommandArgs, "-Ppassword="+args.Password)
-	case args.AccessToken != "":
-		errMsg := "AccessToken is not supported for Gradle" +
-			" try username: <username> , password: <access_token> instead"
-		logrus.Println(errMsg)
-		return cmdList, fmt.Errorf(errMsg)
-	}
 	rtPublishCommandArgs = append(rtPublishCommandArgs, "--build-name="+args.BuildName)
 	rtPublishCommandArgs = append(rtPublishCommandArgs, "--build-number="+args.BuildNumber)
 
-	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
-		"--server-id=" + tmpServerId}
-	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
-	if err != nil {
-		logrus.Println("PopulateArgs error: ", err)
-		return cmdList, err
-	}
-
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, gradleConfigCommandArgs)
 	cmdList = append(cmdList, rtPublishCommandArgs)
-	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
-
-	if IsBuildDiscardArgs(args) {
-		buildDiscardBuildArgsList, err := GetBuildDiscardCommandArgs(args)
-		if err != nil {
-			logrus.Println("GetBuildDiscardCommandArgs error: ", err)
-			return cmdList, err
-		}
-		cmdList = append(cmdList, buildDiscardBuildArgsList...)
-	}
-
 	return cmdList, nil
 }
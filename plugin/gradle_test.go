@@ -25,7 +25,7 @@ func TestGetGradleBuildCommandArgs(t *testing.T) {
 			},
 			output: []string{
 				"config add tmpServerId --url=https://artifactory.test.io/artifactory/ " +
-					"--user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+					"--user=user --password-stdin --interactive=false --overwrite=true",
 				"gradle-config --repo-deploy=" + RtTestRelRepo + " --repo-resolve=" + RtResolveRelRepo,
 				"gradle clean build --build-name=" + RtBuildName + " --build-number=" + RtBuildNumber,
 			},
@@ -74,11 +74,52 @@ func TestGetGradlePublishCommandArgs(t *testing.T) {
 			},
 			output: []string{
 				"config add " + RtDeployerId + " --url=" + RtUrlTestStr +
-					" --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+					" --user=user --password-stdin --interactive=false --overwrite=true",
 				"gradle-config --repo-deploy=" + RtTestRelRepo + " --repo-resolve=" +
 					RtResolveRelRepo + " --server-id-deploy=" + RtDeployerId + " --server-id-resolve=" + RtDeployerId,
-				"gradle publish -Pusername=user -Ppassword=pass --build-name=" +
-					RtBuildName + " --build-number=" + RtBuildNumber,
+				"gradle publish --build-name=" + RtBuildName + " --build-number=" + RtBuildNumber,
+				"rt build-publish " + RtBuildName + " " + RtBuildNumber + " --server-id=" + RtDeployerId,
+			},
+			err: nil,
+		},
+		{
+			args: Args{
+				BuildTool:   "gradle",
+				Command:     "publish",
+				URL:         RtUrlTestStr,
+				AccessToken: RtAccessToken,
+				RepoDeploy:  RtTestRelRepo,
+				BuildName:   RtBuildName,
+				BuildNumber: RtBuildNumber,
+			},
+			output: []string{
+				"config add tmpServerId --url=" + RtUrlTestStr +
+					" --access-token-stdin --interactive=false --overwrite=true",
+				"gradle-config --repo-deploy=" + RtTestRelRepo +
+					" --server-id-deploy=tmpServerId --server-id-resolve=tmpServerId",
+				"gradle publish --build-name=" + RtBuildName + " --build-number=" + RtBuildNumber,
+				"rt build-publish " + RtBuildName + " " + RtBuildNumber + " --server-id=tmpServerId",
+			},
+			err: nil,
+		},
+		{
+			args: Args{
+				BuildTool:   "gradle",
+				Command:     "publish",
+				URL:         RtUrlTestStr,
+				Username:    "ab",
+				APIKey:      "apikey123",
+				RepoDeploy:  RtTestRelRepo,
+				BuildName:   RtBuildName,
+				BuildNumber: RtBuildNumber,
+				DeployerId:  RtDeployerId,
+			},
+			output: []string{
+				"config add " + RtDeployerId + " --url=" + RtUrlTestStr +
+					" --user=ab --password-stdin --interactive=false --overwrite=true",
+				"gradle-config --repo-deploy=" + RtTestRelRepo +
+					" --server-id-deploy=" + RtDeployerId + " --server-id-resolve=" + RtDeployerId,
+				"gradle publish --build-name=" + RtBuildName + " --build-number=" + RtBuildNumber,
 				"rt build-publish " + RtBuildName + " " + RtBuildNumber + " --server-id=" + RtDeployerId,
 			},
 			err: nil,
@@ -86,7 +127,7 @@ func TestGetGradlePublishCommandArgs(t *testing.T) {
 	}
 
 	for _, tc := range tests {
-		result, err := GetGradlePublishCommand(tc.args)
+		result, err := GetRtCommandsList(tc.args)
 		if err != nil {
 			if tc.err == nil {
 				t.Errorf("Unexpected error: %v", err)
@@ -6,6 +6,28 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	RegisterRtCommand(MvnCmd, defaultBuildToolCommand, rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_GOALS"},
+			flagMaps: [][]JsonTagToExeFlagMapStringItem{MavenConfigCmdJsonTagToExeFlagMapStringItemList,
+				MavenRunCmdJsonTagToExeFlagMapStringItemList},
+		},
+		commands: GetMavenBuildCommandArgs,
+	})
+	RegisterRtCommand(MvnCmd, Publish, rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+			flagMaps: [][]JsonTagToExeFlagMapStringItem{MavenConfigCmdJsonTagToExeFlagMapStringItemList,
+				RtBuildInfoPublishCmdJsonTagToExeFlagMap, BuildDiscardCmdJsonTagToExeFlagMapStringItemList},
+		},
+		commands:     GetMavenPublishCommand,
+		postCommands: GetDeployerPublishPostCommands,
+	})
+}
+
 var MavenRunCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
 	{"--build-name=", "PLUGIN_BUILD_NAME", false, false},
 	{"--build-number=", "PLUGIN_BUILD_NUMBER", false, false},
@@ -34,7 +56,7 @@ func GetMavenBuildCommandArgs(args Args) ([][]string, error) {
 
 	var cmdList [][]string
 
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(args.ResolverId,
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(resolverServerId(args),
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
@@ -44,11 +66,14 @@ func GetMavenBuildCommandArgs(args Args) ([][]string, error) {
 	// Add necessary parameters for Windows to prevent all interactive prompts
 	if runtime.GOOS == "windows" {
 		// These parameters prevent all interactive prompts
-		mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--global=true")
+		if args.Global == "" {
+			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--global=true")
+		}
 		// Add server ID for deployment/resolution
 		if args.ResolverId != "" {
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--server-id-resolve="+args.ResolverId)
-			mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--server-id-deploy="+args.ResolverId)
+			if args.ServerIdDeploy == "" {
+				mvnConfigCommandArgs = append(mvnConfigCommandArgs, "--server-id-deploy="+args.ResolverId)
+			}
 		}
 		// Add repos to prevent prompts
 		// Must set both release and snapshot repos to prevent errors
@@ -71,13 +96,13 @@ func GetMavenBuildCommandArgs(args Args) ([][]string, error) {
 		return cmdList, err
 	}
 
-	mvnRunCommandArgs := []string{MvnCmd, args.MvnGoals}
+	mvnRunCommandArgs := append([]string{MvnCmd}, splitCommandLine(args.MvnGoals)...)
 	err = PopulateArgs(&mvnRunCommandArgs, &args, MavenRunCmdJsonTagToExeFlagMapStringItemList)
 	if err != nil {
 		return cmdList, err
 	}
 	if len(args.MvnPomFile) > 0 {
-		mvnRunCommandArgs = append(mvnRunCommandArgs, "-f "+args.MvnPomFile)
+		mvnRunCommandArgs = append(mvnRunCommandArgs, "-f", args.MvnPomFile)
 	}
 
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
@@ -95,8 +120,7 @@ func GetMavenPublishCommand(args Args) ([][]string, error) {
 	var cmdList [][]string
 	var jfrogConfigAddConfigCommandArgs []string
 
-	tmpServerId := args.DeployerId
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(deployerServerId(args),
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
@@ -118,26 +142,8 @@ func GetMavenPublishCommand(args Args) ([][]string, error) {
 		return cmdList, err
 	}
 
-	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
-		"--server-id=" + tmpServerId}
-	err = PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
-	if err != nil {
-		logrus.Println("PopulateArgs error: ", err)
-		return cmdList, err
-	}
-
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, mvnConfigCommandArgs)
 	cmdList = append(cmdList, rtPublishCommandArgs)
-	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
-
-	if IsBuildDiscardArgs(args) {
-		buildDiscardBuildArgsList, err := GetBuildDiscardCommandArgs(args)
-		if err != nil {
-			logrus.Println("GetBuildDiscardCommandArgs error: ", err)
-			return cmdList, err
-		}
-		cmdList = append(cmdList, buildDiscardBuildArgsList...)
-	}
 	return cmdList, nil
 }
@@ -27,7 +27,7 @@ func TestGetMavenBuildCommandUserPassword(t *testing.T) {
 
 	wantCmds := []string{
 		"config add resolve_gen_maven_01 --url=https://artifactory.test.io/artifactory/ " +
-			"--user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+			"--user=ab --password-stdin --interactive=false --overwrite=true",
 		"mvn-config --repo-resolve-releases=mvn_repo_resolve_releases_01 " +
 			"--repo-resolve-snapshots=mvn_repo_resolve_snapshots_01 --server-id-resolve=resolve_gen_maven_01",
 		"mvn clean install --build-name=t2 --build-number=v1.0 -f pom.xml",
@@ -64,7 +64,7 @@ func TestGetMavenBuildCommandAccessToken(t *testing.T) {
 
 	wantCmds := []string{
 		"config add resolve_gen_maven_01 --url=https://artifactory.test.io/artifactory/ " +
-			"--access-token $PLUGIN_ACCESS_TOKEN --interactive=false",
+			"--access-token-stdin --interactive=false --overwrite=true",
 		"mvn-config --repo-resolve-releases=mvn_repo_resolve_releases_01 " +
 			"--repo-resolve-snapshots=mvn_repo_resolve_snapshots_01 --server-id-resolve=resolve_gen_maven_01",
 		"mvn clean install --build-name=t2 --build-number=v1.0 -f pom.xml",
@@ -92,13 +92,13 @@ func TestGetMavenPublishCommandUserNamePassword(t *testing.T) {
 		DeployReleaseRepo:  RtTestRelRepo,
 		DeploySnapshotRepo: RtTestSnapshotRepo,
 	}
-	cmdList, err := GetMavenPublishCommand(args)
+	cmdList, err := GetRtCommandsList(args)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
 	wantCmds := []string{
-		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false --overwrite=true",
 		"mvn-config --repo-deploy-releases=mvn_repo_deploy_releases_01 --repo-deploy-snapshots=mvn_repo_deploy_snapshots_01",
 		"mvn deploy --build-name=t2 --build-number=v1.0",
 		"rt build-publish t2 v1.0 --server-id=deploy_gen_maven_01",
@@ -126,13 +126,13 @@ func TestGetMavenPublishCommandAccessToken(t *testing.T) {
 		DeployReleaseRepo:  RtTestRelRepo,
 		DeploySnapshotRepo: RtTestSnapshotRepo,
 	}
-	cmdList, err := GetMavenPublishCommand(args)
+	cmdList, err := GetRtCommandsList(args)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
 	wantCmds := []string{
-		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --access-token $PLUGIN_ACCESS_TOKEN --interactive=false",
+		"config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --access-token-stdin --interactive=false --overwrite=true",
 		"mvn-config --repo-deploy-releases=mvn_repo_deploy_releases_01 --repo-deploy-snapshots=mvn_repo_deploy_snapshots_01",
 		"mvn deploy --build-name=t2 --build-number=v1.0",
 		"rt build-publish t2 v1.0 --server-id=deploy_gen_maven_01",
@@ -147,3 +147,45 @@ func TestGetMavenPublishCommandAccessToken(t *testing.T) {
 		}
 	}
 }
+
+func TestGetMavenBuildCommandOptions(t *testing.T) {
+	args := Args{
+		AccessToken:     RtAccessToken,
+		BuildTool:       RtMvnBuildTool,
+		MvnGoals:        "clean install",
+		BuildName:       RtBuildName,
+		BuildNumber:     RtBuildNumber,
+		URL:             RtUrlTestStr,
+		ResolverId:      RtRslvId,
+		ServerIdDeploy:  RtRslvId,
+		IncludePatterns: "*.jar",
+		ExcludePatterns: "*-tests.jar",
+		Global:          "false",
+		UseWrapper:      "true",
+		DetailedSummary: "true",
+		Format:          "json",
+		Scan:            "true",
+		Threads:         4,
+	}
+	cmdList, err := GetMavenBuildCommandArgs(args)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add resolve_gen_maven_01 --url=https://artifactory.test.io/artifactory/ " +
+			"--access-token-stdin --interactive=false --overwrite=true",
+		"mvn-config --exclude-patterns=*-tests.jar --global=false --include-patterns=*.jar " +
+			"--server-id-deploy=resolve_gen_maven_01 --server-id-resolve=resolve_gen_maven_01 --use-wrapper=true",
+		"mvn clean install --build-name=t2 --build-number=v1.0 --detailed-summary=true --format=json " +
+			"--scan=true --threads=4",
+	}
+
+	for i, cmd := range cmdList {
+		cmdStr := strings.Join(cmd, " ")
+		ret := strings.Compare(cmdStr, wantCmds[i])
+		if ret != 0 {
+			t.Errorf("Expected: %s, Got: %s", wantCmds[i], cmdStr)
+		}
+	}
+}
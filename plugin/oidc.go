@@ -0,0 +1,157 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	oidcTokenPath        = "/access/api/v1/oidc/token"
+	oidcGrantType        = "urn:ietf:params:oauth:grant-type:token-exchange"
+	oidcSubjectTokenType = "urn:ietf:params:oauth:token-type:id_token"
+
+	// oidcDryRunToken stands in for the access token in dry runs, where the
+	// exchange is not done.
+	oidcDryRunToken = "oidc-dry-run-token"
+)
+
+// checkOIDC returns the problems of the OIDC settings.
+func checkOIDC(args Args) []string {
+	var problems []string
+	switch {
+	case args.OIDCTokenEnv == "" && args.OIDCTokenFile == "":
+		problems = append(problems, "PLUGIN_OIDC_PROVIDER_NAME requires PLUGIN_OIDC_TOKEN_ENV or PLUGIN_OIDC_TOKEN_FILE")
+	case args.OIDCTokenEnv != "" && args.OIDCTokenFile != "":
+		problems = append(problems, "PLUGIN_OIDC_TOKEN_ENV and PLUGIN_OIDC_TOKEN_FILE cannot be set together")
+	}
+	if args.Username != "" || args.Password != "" || args.APIKey != "" || args.AccessToken != "" {
+		problems = append(problems, "PLUGIN_OIDC_PROVIDER_NAME cannot be set together with PLUGIN_USERNAME, "+
+			"PLUGIN_PASSWORD, PLUGIN_API_KEY or PLUGIN_ACCESS_TOKEN")
+	}
+	if args.URL == "" {
+		problems = append(problems, "PLUGIN_OIDC_PROVIDER_NAME requires PLUGIN_URL")
+	}
+	return problems
+}
+
+// withOIDCToken exchanges the identity token issued by the CI for a JFrog
+// access token and returns args authenticating with it.
+func withOIDCToken(ctx context.Context, args Args) (Args, error) {
+	if problems := checkOIDC(args); len(problems) > 0 {
+		return args, &SettingsError{Command: "oidc", Problems: problems}
+	}
+
+	endpoint, err := oidcTokenURL(args.URL)
+	if err != nil {
+		return args, err
+	}
+	if args.DryRun {
+		fmt.Printf("+ POST %s\n", endpoint)
+		args.AccessToken = oidcDryRunToken
+		return args, nil
+	}
+
+	idToken, err := readOIDCIdentityToken(args)
+	if err != nil {
+		return args, err
+	}
+	// mask the identity token before anything can log it
+	args.oidcIdentityToken = idToken
+	redactLogs(args)
+	logrus.Printf("Exchanging the OIDC identity token with provider %s\n", args.OIDCProviderName)
+	token, err := exchangeOIDCToken(ctx, args, endpoint, idToken)
+	if err != nil {
+		return args, err
+	}
+	args.AccessToken = token
+	redactLogs(args)
+	return args, nil
+}
+
+// readOIDCIdentityToken reads the identity token from PLUGIN_OIDC_TOKEN_ENV
+// or PLUGIN_OIDC_TOKEN_FILE.
+func readOIDCIdentityToken(args Args) (string, error) {
+	if args.OIDCTokenFile != "" {
+		data, err := os.ReadFile(args.OIDCTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading oidc token file: %s", err)
+		}
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("oidc token file %s is empty", args.OIDCTokenFile)
+	}
+	if token := strings.TrimSpace(os.Getenv(args.OIDCTokenEnv)); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("environment variable %s is not set", args.OIDCTokenEnv)
+}
+
+// oidcTokenURL returns the token exchange endpoint of the platform serving
+// the Artifactory URL.
+func oidcTokenURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid PLUGIN_URL %q", rawURL)
+	}
+	platformPath := strings.TrimSuffix(u.Path, "/")
+	if i := strings.Index(platformPath, "/artifactory"); i >= 0 {
+		platformPath = platformPath[:i]
+	}
+	u.Path = platformPath + oidcTokenPath
+	u.RawQuery, u.Fragment = "", ""
+	return u.String(), nil
+}
+
+// exchangeOIDCToken posts the identity token to the token exchange endpoint
+// and returns the access token of the response.
+func exchangeOIDCToken(ctx context.Context, args Args, endpoint, idToken string) (string, error) {
+	body, err := json.Marshal(struct {
+		GrantType        string `json:"grant_type"`
+		SubjectTokenType string `json:"subject_token_type"`
+		SubjectToken     string `json:"subject_token"`
+		ProviderName     string `json:"provider_name"`
+		ProjectKey       string `json:"project_key,omitempty"`
+	}{oidcGrantType, oidcSubjectTokenType, idToken, args.OIDCProviderName, args.Project})
+	if err != nil {
+		return "", err
+	}
+
+	httpClient, err := newHTTPClient(args)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error exchanging oidc token: %s", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return "", fmt.Errorf("error exchanging oidc token: %s", err)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("error decoding oidc token response: %s", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("oidc token response has no access_token")
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestOIDCTokenURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://acme.jfrog.io/artifactory/", "https://acme.jfrog.io/access/api/v1/oidc/token"},
+		{"https://acme.jfrog.io/artifactory", "https://acme.jfrog.io/access/api/v1/oidc/token"},
+		{"https://acme.jfrog.io/", "https://acme.jfrog.io/access/api/v1/oidc/token"},
+		{"https://example.com/jfrog/artifactory/api", "https://example.com/jfrog/access/api/v1/oidc/token"},
+	}
+	for _, tt := range tests {
+		got, err := oidcTokenURL(tt.url)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", tt.url, err)
+		} else if got != tt.want {
+			t.Errorf("Expected: %s, Got: %s", tt.want, got)
+		}
+	}
+	if _, err := oidcTokenURL("acme.jfrog.io"); err == nil {
+		t.Errorf("Expected an error for a URL without scheme")
+	}
+}
+
+func TestOIDCUpload(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/access/api/v1/oidc/token":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Unexpected body: %v", err)
+			}
+			if body["subject_token"] != "id-token" || body["provider_name"] != "drone" ||
+				body["grant_type"] != oidcGrantType || body["subject_token_type"] != oidcSubjectTokenType {
+				t.Errorf("Unexpected exchange request: %v", body)
+			}
+			_, _ = w.Write([]byte(`{"access_token":"exchanged-token","expires_in":3600}`))
+		case "/artifactory/libs-release/app.txt":
+			gotAuth = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("id-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := Args{
+		Client:           ClientNative,
+		URL:              server.URL + "/artifactory/",
+		Source:           file,
+		Target:           "libs-release/",
+		Flat:             "true",
+		OIDCProviderName: "drone",
+		OIDCTokenFile:    tokenFile,
+	}
+	if err := Exec(context.Background(), args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotAuth != "Bearer exchanged-token" {
+		t.Errorf("Expected the exchanged token, Got: %s", gotAuth)
+	}
+}
+
+func TestOIDCExchangeFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("invalid token"))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_OIDC_TOKEN", "id-token")
+	args := Args{URL: server.URL + "/artifactory/", OIDCProviderName: "drone", OIDCTokenEnv: "TEST_OIDC_TOKEN"}
+	_, err := withOIDCToken(context.Background(), args)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected the exchange to fail, Got: %v", err)
+	}
+}
+
+func TestOIDCIdentityTokenFileRedacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"exchanged-token"}`))
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-id-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	logrus.SetOutput(&out)
+	defer logrus.SetOutput(os.Stderr)
+
+	args := Args{URL: server.URL + "/artifactory/", OIDCProviderName: "drone", OIDCTokenFile: tokenFile}
+	if _, err := withOIDCToken(context.Background(), args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	logrus.Printf("identity token file-id-token")
+	if strings.Contains(out.String(), "file-id-token") {
+		t.Errorf("Expected the identity token to be masked, Got: %s", out.String())
+	}
+}
+
+func TestCheckOIDC(t *testing.T) {
+	args := Args{OIDCProviderName: "drone", AccessToken: RtAccessToken}
+	got := strings.Join(checkOIDC(args), "; ")
+	want := "PLUGIN_OIDC_PROVIDER_NAME requires PLUGIN_OIDC_TOKEN_ENV or PLUGIN_OIDC_TOKEN_FILE; " +
+		"PLUGIN_OIDC_PROVIDER_NAME cannot be set together with PLUGIN_USERNAME, PLUGIN_PASSWORD, " +
+		"PLUGIN_API_KEY or PLUGIN_ACCESS_TOKEN; PLUGIN_OIDC_PROVIDER_NAME requires PLUGIN_URL"
+	if got != want {
+		t.Errorf("Expected: %s, Got: %s", want, got)
+	}
+}
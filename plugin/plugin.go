@@ -10,14 +10,19 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	passwordStdinFlag    = "--password-stdin"
+	accessTokenStdinFlag = "--access-token-stdin"
+)
+
 const (
 	harnessHTTPProxy  = "HARNESS_HTTP_PROXY"
 	harnessHTTPSProxy = "HARNESS_HTTPS_PROXY"
@@ -48,6 +53,7 @@ type Args struct {
 	Threads          int    `envconfig:"PLUGIN_THREADS"`
 	SpecVars         string `envconfig:"PLUGIN_SPEC_VARS"`
 	TargetProps      string `envconfig:"PLUGIN_TARGET_PROPS"`
+	AutoProps        bool   `envconfig:"PLUGIN_AUTO_PROPS"`
 	Insecure         string `envconfig:"PLUGIN_INSECURE"`
 	PEMFileContents  string `envconfig:"PLUGIN_PEM_FILE_CONTENTS"`
 	PEMFilePath      string `envconfig:"PLUGIN_PEM_FILE_PATH"`
@@ -55,6 +61,60 @@ type Args struct {
 	BuildName        string `envconfig:"PLUGIN_BUILD_NAME"`
 	PublishBuildInfo bool   `envconfig:"PLUGIN_PUBLISH_BUILD_INFO"`
 	EnableProxy      string `envconfig:"PLUGIN_ENABLE_PROXY"`
+	Client           string `envconfig:"PLUGIN_CLIENT"`
+	DryRun           bool   `envconfig:"PLUGIN_DRY_RUN"`
+
+	// The credential files hold the secrets instead of the settings above,
+	// keeping them out of the environment.
+	PasswordFile    string `envconfig:"PLUGIN_PASSWORD_FILE"`
+	APIKeyFile      string `envconfig:"PLUGIN_API_KEY_FILE"`
+	AccessTokenFile string `envconfig:"PLUGIN_ACCESS_TOKEN_FILE"`
+	PEMFile         string `envconfig:"PLUGIN_PEM_FILE"`
+
+	// Upload options of PLUGIN_SOURCE uploads, Exclusions, Recursive and
+	// Regexp are shared with the add dependencies command.
+	Explode     string `envconfig:"PLUGIN_EXPLODE"`
+	Symlinks    string `envconfig:"PLUGIN_SYMLINKS"`
+	IncludeDirs string `envconfig:"PLUGIN_INCLUDE_DIRS"`
+	Archive     string `envconfig:"PLUGIN_ARCHIVE"`
+	SyncDeletes string `envconfig:"PLUGIN_SYNC_DELETES"`
+
+	// VerifyChecksums compares the checksums of the uploaded files with the
+	// ones stored by Artifactory, ManifestFile receives the verified ones.
+	VerifyChecksums bool   `envconfig:"PLUGIN_VERIFY_CHECKSUMS"`
+	ManifestFile    string `envconfig:"PLUGIN_MANIFEST_FILE"`
+
+	// OIDC exchanges the identity token issued by the CI, read from the
+	// environment variable or file, for an access token.
+	OIDCProviderName string `envconfig:"PLUGIN_OIDC_PROVIDER_NAME"`
+	OIDCTokenEnv     string `envconfig:"PLUGIN_OIDC_TOKEN_ENV"`
+	OIDCTokenFile    string `envconfig:"PLUGIN_OIDC_TOKEN_FILE"`
+
+	// The proxy of every command, see resolveProxy. ProxyPasswordFile holds
+	// the proxy password instead of ProxyPassword.
+	HTTPProxy         string `envconfig:"PLUGIN_HTTP_PROXY"`
+	HTTPSProxy        string `envconfig:"PLUGIN_HTTPS_PROXY"`
+	NoProxy           string `envconfig:"PLUGIN_NO_PROXY"`
+	ProxyUsername     string `envconfig:"PLUGIN_PROXY_USERNAME"`
+	ProxyPassword     string `envconfig:"PLUGIN_PROXY_PASSWORD"`
+	ProxyPasswordFile string `envconfig:"PLUGIN_PROXY_PASSWORD_FILE"`
+
+	// Servers lists additional servers uploads are mirrored to, see Server.
+	Servers       string `envconfig:"PLUGIN_SERVERS"`
+	ServersPolicy string `envconfig:"PLUGIN_SERVERS_POLICY"`
+
+	// ResultFile and OutputFile receive the Result of the step.
+	ResultFile string `envconfig:"PLUGIN_RESULT_FILE"`
+	OutputFile string `envconfig:"DRONE_OUTPUT"`
+
+	// Timeout limits the whole step, CommandTimeout each jf invocation.
+	Timeout        time.Duration `envconfig:"PLUGIN_TIMEOUT"`
+	CommandTimeout time.Duration `envconfig:"PLUGIN_COMMAND_TIMEOUT"`
+
+	// Retry settings of every command run by the step, see retryPolicy.
+	RetryMaxAttempts int           `envconfig:"PLUGIN_RETRY_MAX_ATTEMPTS"`
+	RetryBackoff     time.Duration `envconfig:"PLUGIN_RETRY_BACKOFF"`
+	RetryMaxBackoff  time.Duration `envconfig:"PLUGIN_RETRY_MAX_BACKOFF"`
 
 	// RT commands
 	BuildTool string `envconfig:"PLUGIN_BUILD_TOOL"`
@@ -70,6 +130,15 @@ type Args struct {
 	MvnPomFile          string `envconfig:"PLUGIN_POM_FILE"`
 	DeployerId          string `envconfig:"PLUGIN_DEPLOYER_ID"`
 	ResolverId          string `envconfig:"PLUGIN_RESOLVER_ID"`
+	ServerIdDeploy      string `envconfig:"PLUGIN_SERVER_ID_DEPLOY"`
+	ServerIdResolve     string `envconfig:"PLUGIN_SERVER_ID_RESOLVE"`
+	IncludePatterns     string `envconfig:"PLUGIN_INCLUDE_PATTERNS"`
+	ExcludePatterns     string `envconfig:"PLUGIN_EXCLUDE_PATTERNS"`
+	Global              string `envconfig:"PLUGIN_GLOBAL"`
+	UseWrapper          string `envconfig:"PLUGIN_USE_WRAPPER"`
+	DetailedSummary     string `envconfig:"PLUGIN_DETAILED_SUMMARY"`
+	Format              string `envconfig:"PLUGIN_FORMAT"`
+	Scan                string `envconfig:"PLUGIN_SCAN"`
 
 	// Gradle commands
 	GradleTasks string `envconfig:"PLUGIN_TASKS"`
@@ -77,6 +146,12 @@ type Args struct {
 	RepoDeploy  string `envconfig:"PLUGIN_REPO_DEPLOY"`
 	RepoResolve string `envconfig:"PLUGIN_REPO_RESOLVE"`
 
+	DeployIvyDesc       string `envconfig:"PLUGIN_DEPLOY_IVY_DESC"`
+	DeployMavenDesc     string `envconfig:"PLUGIN_DEPLOY_MAVEN_DESC"`
+	IvyArtifactsPattern string `envconfig:"PLUGIN_IVY_ARTIFACTS_PATTERN"`
+	IvyDescPattern      string `envconfig:"PLUGIN_IVY_DESC_PATTERN"`
+	UsesPlugin          string `envconfig:"PLUGIN_USES_PLUGIN"`
+
 	// Upload Download commands
 	SpecPath string `envconfig:"PLUGIN_SPEC_PATH"`
 	Module   string `envconfig:"PLUGIN_MODULE"`
@@ -91,6 +166,7 @@ type Args struct {
 	Recursive         string `envconfig:"PLUGIN_RECURSIVE"`
 	Regexp            string `envconfig:"PLUGIN_REGEXP"`
 	DependencyPattern string `envconfig:"PLUGIN_DEPENDENCY"`
+	ServerId          string `envconfig:"PLUGIN_SERVER_ID"`
 
 	// Build Discard commands
 	Async           string `envconfig:"PLUGIN_ASYNC"`
@@ -98,129 +174,185 @@ type Args struct {
 	ExcludeBuilds   string `envconfig:"PLUGIN_EXCLUDE_BUILDS"`
 	MaxBuilds       string `envconfig:"PLUGIN_MAX_BUILDS"`
 	MaxDays         string `envconfig:"PLUGIN_MAX_DAYS"`
+
+	// Post actions, see postActions
+	BuildClean           string `envconfig:"PLUGIN_BUILD_CLEAN"`
+	PostActionsOnFailure bool   `envconfig:"PLUGIN_POST_ACTIONS_ON_FAILURE"`
+
+	// result records the commands run and the artifacts transferred when the
+	// step result is written, see wantsResult.
+	result *resultRecorder
+
+	// workspace holds the files generated for the step.
+	workspace *workspace
+
+	// oidcIdentityToken is the identity token exchanged for AccessToken,
+	// kept to mask it, see withOIDCToken.
+	oidcIdentityToken string
+
+	// serverName is the name of the PLUGIN_SERVERS server args target, empty
+	// for the primary server, see Server.apply.
+	serverName string
 }
 
 // Exec executes the plugin.
 func Exec(ctx context.Context, args Args) error {
 
-	logrus.Println("Checking RT commands")
-	if args.BuildTool != "" || args.Command != "" {
-		logrus.Println("Handling rt command handleRtCommand")
-		return HandleRtCommands(args)
+	args, err := withCredentialFiles(args)
+	if err != nil {
+		return err
 	}
+	redactLogs(args)
+	args = withBuildDefaults(args)
 
-	enableProxy := parseBoolOrDefault(false, args.EnableProxy)
-	if enableProxy {
-		logrus.Printf("setting proxy config for upload")
-		setSecureConnectProxies()
+	proxy, err := resolveProxy(args)
+	if err != nil {
+		return err
 	}
+	proxy.apply()
+
+	args.workspace = &workspace{}
+	defer func() {
+		if err := args.workspace.cleanup(); err != nil {
+			logrus.Println("Error removing workspace: ", err)
+		}
+	}()
 
-	// write code here
-	if args.URL == "" {
-		return fmt.Errorf("JFrog Artifactory URL must be set, or anonymous access is not permitted")
+	if !wantsResult(args) {
+		return run(ctx, args)
 	}
 
-	cmdArgs := []string{getJfrogBin(), "rt", "u", fmt.Sprintf("--url %s", args.URL)}
-	if args.Retries != 0 {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--retries=%d", args.Retries))
+	args.result = newResultRecorder()
+	if args.DetailedSummary == "" {
+		args.DetailedSummary = "true"
+	}
+	err = run(ctx, args)
+	if writeErr := writeResult(args, args.result.finish(args, err)); writeErr != nil {
+		if err == nil {
+			return writeErr
+		}
+		logrus.Println("Error writing result: ", writeErr)
 	}
+	return err
+}
 
-	// Set authentication params
-	cmdArgs, error := setAuthParams(cmdArgs, args)
-	if error != nil {
-		return error
+// buildDefaultsCommands lists the commands recording build-info, for which
+// the build name and number are defaulted. The other commands use them to
+// select what to act on, such as the builds build-discard deletes, so they
+// must be set explicitly.
+var buildDefaultsCommands = map[string]bool{
+	uploadCommandName: true,
+	Publish:           true,
+	"scan":            true,
+	"promote":         true,
+}
+
+// withBuildDefaults defaults the build name to the repository slug and the
+// build number to the pipeline build number.
+func withBuildDefaults(args Args) Args {
+	command := args.Command
+	if command == "" && args.BuildTool == "" {
+		command = uploadCommandName
 	}
+	if !buildDefaultsCommands[command] {
+		return args
+	}
+	if args.BuildName == "" && args.Repo.Slug != "" {
+		args.BuildName = strings.ReplaceAll(args.Repo.Slug, "/", "-")
+		logrus.Printf("Using build name %q from the repository\n", args.BuildName)
+	}
+	if args.BuildNumber == "" && args.Build.Number != 0 {
+		args.BuildNumber = strconv.Itoa(args.Build.Number)
+		logrus.Printf("Using build number %q from the pipeline\n", args.BuildNumber)
+	}
+	return args
+}
 
-	flat := parseBoolOrDefault(false, args.Flat)
-	cmdArgs = append(cmdArgs, fmt.Sprintf("--flat=%s", strconv.FormatBool(flat)))
+func run(ctx context.Context, args Args) error {
 
-	if args.Threads > 0 {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--threads=%d", args.Threads))
+	args, err := renderTemplates(args)
+	if err != nil {
+		return err
 	}
-	// Set insecure flag
-	insecure := parseBoolOrDefault(false, args.Insecure)
-	if insecure {
-		cmdArgs = append(cmdArgs, "--insecure-tls")
+
+	if args.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.Timeout)
+		defer cancel()
 	}
 
-	// Add --build-number and --build-name flags if provided
-	if args.BuildNumber != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--build-number=%s", args.BuildNumber))
+	if args.DryRun {
+		logrus.Println("Dry run, the commands are printed and not executed")
 	}
-	if args.BuildName != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--build-name='%s'", args.BuildName))
-	}
-
-	// create pem file
-	if args.PEMFileContents != "" && !insecure {
-		var path string
-		// figure out path to write pem file
-		if args.PEMFilePath == "" {
-			if runtime.GOOS == "windows" {
-				path = "C:/users/ContainerAdministrator/.jfrog/security/certs/cert.pem"
-			} else {
-				path = "/root/.jfrog/security/certs/cert.pem"
-			}
-		} else {
-			path = args.PEMFilePath
-		}
-		fmt.Printf("Creating pem file at %q\n", path)
-		// write pen contents to path
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			// remove filename from path
-			dir := filepath.Dir(path)
-			pemFolderErr := os.MkdirAll(dir, 0700)
-			if pemFolderErr != nil {
-				return fmt.Errorf("error creating pem folder: %s", pemFolderErr)
-			}
-			// write pem contents
-			pemWriteErr := os.WriteFile(path, []byte(args.PEMFileContents), 0600)
-			if pemWriteErr != nil {
-				return fmt.Errorf("error writing pem file: %s", pemWriteErr)
-			}
-			fmt.Printf("Successfully created pem file at %q\n", path)
+
+	if args.OIDCProviderName != "" {
+		if args, err = withOIDCToken(ctx, args); err != nil {
+			return err
 		}
 	}
+
+	if args.BuildTool == "" && args.Command == "" {
+		// without build tool nor command the settings describe an upload
+		args.Command = uploadCommandName
+	}
+	return HandleRtCommands(ctx, args)
+}
+
+// upload uploads the files to the server of args. With the native client it
+// publishes the build-info when PLUGIN_PUBLISH_BUILD_INFO is set.
+func upload(ctx context.Context, args Args) error {
+	if args.VerifyChecksums && args.DetailedSummary == "" {
+		// the detailed summary lists the files jf uploaded
+		args.DetailedSummary = "true"
+	}
+	client, err := newArtifactoryClient(args)
+	if err != nil {
+		return err
+	}
+
+	var autoProps string
+	if args.AutoProps {
+		autoProps = autoTargetProps(args.Pipeline)
+	}
+
+	var artifacts []ArtifactDetails
 	// Take in spec file or use source/target arguments
 	if args.Spec != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--spec=%s", args.Spec))
-		if args.SpecVars != "" {
-			cmdArgs = append(cmdArgs, fmt.Sprintf("--spec-vars='%s'", args.SpecVars))
+		jc, ok := client.(*jfClient)
+		if !ok {
+			return fmt.Errorf("spec uploads are only supported by the %q client", ClientJf)
 		}
-	} else {
-		filteredTargetProps := filterTargetProps(args.TargetProps)
-		if filteredTargetProps != "" {
-			cmdArgs = append(cmdArgs, fmt.Sprintf("--target-props='%s'", filteredTargetProps))
+		if artifacts, err = jc.UploadSpec(ctx, args.Spec, args.SpecVars, autoProps); err != nil {
+			return err
 		}
+	} else {
 		if args.Source == "" {
 			return fmt.Errorf("source file needs to be set")
 		}
 		if args.Target == "" {
 			return fmt.Errorf("target path needs to be set")
 		}
-		cmdArgs = append(cmdArgs, fmt.Sprintf("\"%s\"", args.Source), args.Target)
+		props := joinProps(filterTargetProps(args.TargetProps), autoProps)
+		artifacts, err = client.Upload(ctx, args.Source, args.Target, props)
+		if err != nil {
+			return err
+		}
+		// runCommand records the artifacts of the jf output
+		if _, ok := client.(*jfClient); !ok {
+			args.result.addArtifacts(artifacts)
+		}
 	}
 
-	cmdStr := strings.Join(cmdArgs[:], " ")
-
-	shell, shArg := getShell()
-
-	cmd := exec.Command(shell, shArg, cmdStr)
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, "JFROG_CLI_OFFER_CONFIG=false")
-
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	trace(cmd)
-
-	err := cmd.Run()
-	if err != nil {
-		return err
+	if args.VerifyChecksums {
+		if err := verifyUpload(ctx, args, artifacts); err != nil {
+			return err
+		}
 	}
 
-	// Call publishBuildInfo if PLUGIN_PUBLISH_BUILD_INFO is set to true
-	if args.PublishBuildInfo {
-		if err := publishBuildInfo(args); err != nil {
+	// the build-info of jf uploads is published as a post action, the native
+	// client publishes the artifacts it uploaded
+	if _, ok := client.(*jfClient); !ok && args.PublishBuildInfo {
+		if err := client.PublishBuildInfo(ctx, args.BuildName, args.BuildNumber, artifacts); err != nil {
 			return err
 		}
 	}
@@ -228,47 +360,50 @@ func Exec(ctx context.Context, args Args) error {
 	return nil
 }
 
-func publishBuildInfo(args Args) error {
-	if args.BuildName == "" || args.BuildNumber == "" {
-		return fmt.Errorf("both build name and build number need to be set when publishing build info")
+// getUploadCommandArgs returns the jf upload command with the connection and
+// upload flags, without the spec or source and target arguments. Credentials
+// are read from the server config added under serverId.
+func getUploadCommandArgs(args Args, serverId string) []string {
+	cmdArgs := []string{getJfrogBin(), "rt", "u", "--url=" + args.URL, "--server-id=" + serverId}
+	if args.Retries != 0 {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--retries=%d", args.Retries))
 	}
 
-	sanitizedURL, err := sanitizeURL(args.URL)
-	if err != nil {
-		return err
-	}
+	flat := parseBoolOrDefault(false, args.Flat)
+	cmdArgs = append(cmdArgs, fmt.Sprintf("--flat=%s", strconv.FormatBool(flat)))
 
-	publishCmdArgs := []string{
-		getJfrogBin(),
-		"rt",
-		"build-publish",
-		"\"" + args.BuildName + "\"",
-		"\"" + args.BuildNumber + "\"",
-		fmt.Sprintf("--url=%s", sanitizedURL),
+	if args.Threads > 0 {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--threads=%d", args.Threads))
 	}
-
-	if args.AccessToken != "" {
-		publishCmdArgs = append(publishCmdArgs, fmt.Sprintf("--access-token=%sPLUGIN_ACCESS_TOKEN", getEnvPrefix()))
-	} else if args.Username != "" && args.Password != "" {
-		publishCmdArgs = append(publishCmdArgs, fmt.Sprintf("--user=%sPLUGIN_USERNAME", getEnvPrefix()))
-		publishCmdArgs = append(publishCmdArgs, fmt.Sprintf("--password=%sPLUGIN_PASSWORD", getEnvPrefix()))
-	} else {
-		return fmt.Errorf("either access token or username/password need to be set for publishing build info")
+	// Set insecure flag
+	if parseBoolOrDefault(false, args.Insecure) {
+		cmdArgs = append(cmdArgs, "--insecure-tls")
 	}
 
-	publishCmdStr := strings.Join(publishCmdArgs, " ")
-	shell, shArg := getShell()
-	publishCmd := exec.Command(shell, shArg, publishCmdStr)
-	publishCmd.Env = os.Environ()
-	publishCmd.Env = append(publishCmd.Env, "JFROG_CLI_OFFER_CONFIG=false")
-	publishCmd.Stdout = os.Stdout
-	publishCmd.Stderr = os.Stderr
-	trace(publishCmd)
-
-	if err := publishCmd.Run(); err != nil {
-		return fmt.Errorf("error publishing build info: %s", err)
+	// Add --build-number and --build-name flags if provided
+	if args.BuildNumber != "" {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--build-number=%s", args.BuildNumber))
+	}
+	if args.BuildName != "" {
+		cmdArgs = append(cmdArgs, "--build-name="+args.BuildName)
 	}
+	if args.DetailedSummary != "" {
+		cmdArgs = append(cmdArgs, "--detailed-summary="+args.DetailedSummary)
+	}
+	return cmdArgs
+}
 
+func publishBuildInfo(ctx context.Context, args Args) error {
+	cmdList, err := GetPublishBuildInfoPostCommandArgs(args)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmdList {
+		execArgs := append([]string{getJfrogBin()}, cmd...)
+		if err := runCommand(ctx, args, execArgs, os.Stdout); err != nil {
+			return fmt.Errorf("error publishing build info: %s", err)
+		}
+	}
 	return nil
 }
 
@@ -298,6 +433,53 @@ func filterTargetProps(rawProps string) string {
 	return strings.Join(validPairs, ",")
 }
 
+// autoTargetProps returns the VCS and CI metadata of the pipeline as
+// properties, in the jf notation key1=value1;key2=value2. Semicolons separate
+// the properties and are dropped from the values.
+func autoTargetProps(p Pipeline) string {
+	branch := p.Commit.Branch
+	if branch == "" {
+		branch = p.Build.Branch
+	}
+	vcsURL := p.Git.HTTPURL
+	if vcsURL == "" {
+		vcsURL = p.Repo.Link
+	}
+	var prNumber string
+	if p.PullRequest.Number != 0 {
+		prNumber = strconv.Itoa(p.PullRequest.Number)
+	}
+
+	var props []string
+	for _, prop := range []struct{ key, value string }{
+		{"vcs.revision", p.Commit.Rev},
+		{"vcs.branch", branch},
+		{"vcs.url", vcsURL},
+		{"build.link", p.Build.Link},
+		{"ci.event", p.Build.Event},
+		{"pr.number", prNumber},
+		{"vcs.tag", p.Tag.Name},
+		{"semver", p.Semver.Version},
+	} {
+		value := strings.ReplaceAll(prop.value, ";", "")
+		if value != "" {
+			props = append(props, prop.key+"="+value)
+		}
+	}
+	return strings.Join(props, ";")
+}
+
+// joinProps joins the non empty property lists.
+func joinProps(props ...string) string {
+	var nonEmpty []string
+	for _, p := range props {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ";")
+}
+
 // sanitizeURL trims the URL to include only up to the '/artifactory/' path.
 func sanitizeURL(inputURL string) (string, error) {
 	parsedURL, err := url.Parse(inputURL)
@@ -318,35 +500,36 @@ func sanitizeURL(inputURL string) (string, error) {
 	return parsedURL.String(), nil
 }
 
-// setAuthParams appends authentication parameters to cmdArgs based on the provided credentials.
+// setAuthParams appends the jf config authentication parameters to cmdArgs based
+// on the provided credentials. Secrets are never added to the command line, the
+// config command reads them from stdin instead, see getAuthSecret.
 func setAuthParams(cmdArgs []string, args Args) ([]string, error) {
 	// Set authentication params
-	envPrefix := getEnvPrefix()
 	if args.Username != "" && args.Password != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--user %sPLUGIN_USERNAME", envPrefix))
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--password %sPLUGIN_PASSWORD", envPrefix))
-	} else if args.APIKey != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--apikey %sPLUGIN_API_KEY", envPrefix))
+		cmdArgs = append(cmdArgs, "--user="+args.Username)
+		cmdArgs = append(cmdArgs, passwordStdinFlag)
+	} else if args.Username != "" && args.APIKey != "" {
+		// Artifactory accepts an api key in place of the password
+		cmdArgs = append(cmdArgs, "--user="+args.Username)
+		cmdArgs = append(cmdArgs, passwordStdinFlag)
 	} else if args.AccessToken != "" {
-		cmdArgs = append(cmdArgs, fmt.Sprintf("--access-token %sPLUGIN_ACCESS_TOKEN", envPrefix))
+		cmdArgs = append(cmdArgs, accessTokenStdinFlag)
 	} else {
 		return nil, fmt.Errorf("either username/password, api key or access token needs to be set")
 	}
 	return cmdArgs, nil
 }
 
-func getShell() (string, string) {
-	if runtime.GOOS == "windows" {
-		// First check for PowerShell Core (pwsh.exe) which is used in PowerShell Nanoserver
-		if _, err := os.Stat("C:/Program Files/PowerShell/pwsh.exe"); err == nil {
-			return "pwsh", "-Command"
-		}
-
-		// Fall back to traditional PowerShell
-		return "powershell", "-Command"
+// getAuthSecret returns the secret matching the flags added by setAuthParams.
+func getAuthSecret(args Args) string {
+	switch {
+	case args.Username != "" && args.Password != "":
+		return args.Password
+	case args.Username != "" && args.APIKey != "":
+		return args.APIKey
+	default:
+		return args.AccessToken
 	}
-
-	return "sh", "-c"
 }
 
 func getJfrogBin() string {
@@ -358,13 +541,6 @@ func getJfrogBin() string {
 	return "jf"
 }
 
-func getEnvPrefix() string {
-	if runtime.GOOS == "windows" {
-		return "$Env:"
-	}
-	return "$"
-}
-
 func parseBoolOrDefault(defaultValue bool, s string) (result bool) {
 	var err error
 	result, err = strconv.ParseBool(s)
@@ -377,23 +553,19 @@ func parseBoolOrDefault(defaultValue bool, s string) (result bool) {
 
 // trace writes each command to stdout with the command wrapped in an xml
 // tag so that it can be extracted and displayed in the logs.
-func trace(cmd *exec.Cmd) {
-	fmt.Fprintf(os.Stdout, "+ %s\n", strings.Join(cmd.Args, " "))
-}
-
-func setSecureConnectProxies() {
-	copyEnvVariableIfExists(harnessHTTPProxy, httpProxy)
-	copyEnvVariableIfExists(harnessHTTPSProxy, httpsProxy)
-	copyEnvVariableIfExists(harnessNoProxy, noProxy)
+func trace(r *redactor, cmd *exec.Cmd) {
+	fmt.Fprintf(os.Stdout, "+ %s\n", r.redact(formatCommand(cmd.Args)))
 }
 
-func copyEnvVariableIfExists(src string, dest string) {
-	srcValue := os.Getenv(src)
-	if srcValue == "" {
-		return
-	}
-	err := os.Setenv(dest, srcValue)
-	if err != nil {
-		logrus.Printf("Failed to copy env variable from %s to %s with error %v", src, dest, err)
+// formatCommand joins the command arguments for display, quoting the
+// arguments that would otherwise be ambiguous.
+func formatCommand(cmdArgs []string) string {
+	quoted := make([]string, len(cmdArgs))
+	for i, arg := range cmdArgs {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
 	}
+	return strings.Join(quoted, " ")
 }
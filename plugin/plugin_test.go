@@ -5,6 +5,7 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
 	"testing"
 )
@@ -34,21 +35,21 @@ func TestSetAuthParams(t *testing.T) {
 		{
 			cmdArgs: []string{"executable", "arg1", "arg2"},
 			args:    Args{Username: "john", Password: "password123", APIKey: "", AccessToken: ""},
-			output:  []string{"executable", "arg1", "arg2", "--user $PLUGIN_USERNAME", "--password $PLUGIN_PASSWORD"},
+			output:  []string{"executable", "arg1", "arg2", "--user=john", "--password-stdin"},
 			err:     nil,
 		},
 		// Test case 2
 		{
 			cmdArgs: []string{"./app", "--flag"},
 			args:    Args{Username: "", Password: "", APIKey: "secretkey", AccessToken: ""},
-			output:  []string{"./app", "--flag", "--apikey $PLUGIN_API_KEY"},
-			err:     nil,
+			output:  nil,
+			err:     fmt.Errorf("either username/password, api key or access token needs to be set"),
 		},
 		// Test case 3
 		{
 			cmdArgs: []string{"script.sh", "-option"},
 			args:    Args{Username: "", Password: "", APIKey: "", AccessToken: "token123"},
-			output:  []string{"script.sh", "-option", "--access-token $PLUGIN_ACCESS_TOKEN"},
+			output:  []string{"script.sh", "-option", "--access-token-stdin"},
 			err:     nil,
 		},
 		// Test case 4
@@ -62,7 +63,7 @@ func TestSetAuthParams(t *testing.T) {
 		{
 			cmdArgs: []string{"app", "-flag"},
 			args:    Args{Username: "user", Password: "", APIKey: "apikey123", AccessToken: ""},
-			output:  []string{"app", "-flag", "--apikey $PLUGIN_API_KEY"},
+			output:  []string{"app", "-flag", "--user=user", "--password-stdin"},
 			err:     nil,
 		},
 	}
@@ -141,3 +142,85 @@ func TestSanitizeURL(t *testing.T) {
 		}
 	}
 }
+
+func TestWithBuildDefaults(t *testing.T) {
+	pipelineArgs := func(command, buildName, buildNumber string) Args {
+		args := Args{Command: command, BuildName: buildName, BuildNumber: buildNumber}
+		args.Repo.Slug = "octocat/hello-world"
+		args.Build.Number = 42
+		return args
+	}
+
+	tests := []struct {
+		args        Args
+		buildName   string
+		buildNumber string
+	}{
+		{pipelineArgs("", "", ""), "octocat-hello-world", "42"},
+		{pipelineArgs("publish", "", ""), "octocat-hello-world", "42"},
+		{pipelineArgs("promote", RtBuildName, ""), RtBuildName, "42"},
+		{pipelineArgs("scan", "", RtBuildNumber), "octocat-hello-world", RtBuildNumber},
+		{pipelineArgs("download", "", ""), "", ""},
+		{pipelineArgs("build-discard", "", ""), "", ""},
+		{pipelineArgs("cleanup", "", ""), "", ""},
+		{Args{Command: "publish"}, "", ""},
+	}
+
+	for _, tc := range tests {
+		result := withBuildDefaults(tc.args)
+		if result.BuildName != tc.buildName || result.BuildNumber != tc.buildNumber {
+			t.Errorf("For command %q, Expected: %s %s, Got: %s %s", tc.args.Command,
+				tc.buildName, tc.buildNumber, result.BuildName, result.BuildNumber)
+		}
+	}
+}
+
+func TestBuildDiscardRequiresExplicitBuildName(t *testing.T) {
+	args := Args{
+		URL:             RtUrlTestStr,
+		AccessToken:     RtAccessToken,
+		Command:         "build-discard",
+		MaxBuilds:       "5",
+		DeleteArtifacts: "true",
+	}
+	args.Repo.Slug = "octocat/hello-world"
+	args.Build.Number = 42
+
+	err := Exec(context.Background(), args)
+	want := "invalid settings for build-discard: missing PLUGIN_BUILD_NAME"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+}
+
+func TestAutoTargetProps(t *testing.T) {
+	var p Pipeline
+	p.Commit.Rev = "6d2f1a8"
+	p.Commit.Branch = "feature;x"
+	p.Git.HTTPURL = "https://github.com/octocat/hello-world.git"
+	p.Build.Link = "https://drone.test.io/octocat/hello-world/42"
+	p.Build.Event = "pull_request"
+	p.PullRequest.Number = 7
+
+	want := "vcs.revision=6d2f1a8;vcs.branch=featurex;vcs.url=https://github.com/octocat/hello-world.git;" +
+		"build.link=https://drone.test.io/octocat/hello-world/42;ci.event=pull_request;pr.number=7"
+	if result := autoTargetProps(p); result != want {
+		t.Errorf("Expected: %s, Got: %s", want, result)
+	}
+
+	p = Pipeline{}
+	p.Build.Branch = "main"
+	p.Repo.Link = "https://github.com/octocat/hello-world"
+	p.Tag.Name = "v1.2.3"
+	p.Semver.Version = "1.2.3"
+	want = "vcs.branch=main;vcs.url=https://github.com/octocat/hello-world;vcs.tag=v1.2.3;semver=1.2.3"
+	if result := autoTargetProps(p); result != want {
+		t.Errorf("Expected: %s, Got: %s", want, result)
+	}
+}
+
+func TestJoinProps(t *testing.T) {
+	if result := joinProps("", "a=1", "", "b=2;c=3"); result != "a=1;b=2;c=3" {
+		t.Errorf("Unexpected props: %s", result)
+	}
+}
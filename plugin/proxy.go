@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// proxySettings are the proxy variables set for the whole step, empty
+// values leave the inherited variables unchanged.
+type proxySettings struct {
+	httpProxy  string
+	httpsProxy string
+	noProxy    string
+}
+
+// resolveProxy returns the proxy of the step: PLUGIN_HTTP_PROXY,
+// PLUGIN_HTTPS_PROXY and PLUGIN_NO_PROXY, defaulting to the Harness proxy
+// variables with PLUGIN_ENABLE_PROXY. PLUGIN_PROXY_USERNAME and
+// PLUGIN_PROXY_PASSWORD are added to the proxy URLs.
+func resolveProxy(args Args) (proxySettings, error) {
+	enabled := parseBoolOrDefault(false, args.EnableProxy)
+	setting := func(value, harnessVar string) string {
+		if value == "" && enabled {
+			return os.Getenv(harnessVar)
+		}
+		return value
+	}
+
+	httpValue := setting(args.HTTPProxy, harnessHTTPProxy)
+	httpsValue := setting(args.HTTPSProxy, harnessHTTPSProxy)
+
+	var problems []string
+	var settings proxySettings
+	for _, proxy := range []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{"PLUGIN_HTTP_PROXY", httpValue, &settings.httpProxy},
+		{"PLUGIN_HTTPS_PROXY", httpsValue, &settings.httpsProxy},
+	} {
+		if proxy.value == "" {
+			continue
+		}
+		proxyURL, err := parseProxyURL(proxy.value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s", proxy.name, err))
+			continue
+		}
+		if args.ProxyUsername != "" {
+			if proxyURL.User != nil {
+				problems = append(problems, fmt.Sprintf("PLUGIN_PROXY_USERNAME cannot be used with the credentials of %s", proxy.name))
+				continue
+			}
+			proxyURL.User = url.User(args.ProxyUsername)
+			if args.ProxyPassword != "" {
+				proxyURL.User = url.UserPassword(args.ProxyUsername, args.ProxyPassword)
+			}
+		}
+		*proxy.dest = proxyURL.String()
+	}
+
+	switch {
+	case args.ProxyPassword != "" && args.ProxyUsername == "":
+		problems = append(problems, "PLUGIN_PROXY_PASSWORD requires PLUGIN_PROXY_USERNAME")
+	case args.ProxyUsername != "" && httpValue == "" && httpsValue == "":
+		problems = append(problems, "PLUGIN_PROXY_USERNAME requires PLUGIN_HTTP_PROXY or PLUGIN_HTTPS_PROXY")
+	}
+
+	var hosts []string
+	for _, host := range strings.Split(setting(args.NoProxy, harnessNoProxy), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	settings.noProxy = strings.Join(hosts, ",")
+
+	if len(problems) > 0 {
+		return proxySettings{}, &SettingsError{Command: "proxy", Problems: problems}
+	}
+	return settings, nil
+}
+
+// parseProxyURL parses an http or https proxy URL, such as
+// http://proxy.example.com:3128.
+func parseProxyURL(value string) (*url.URL, error) {
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("is not a valid URL: %s", err)
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return nil, fmt.Errorf("must be an http or https URL, got %q", redactProxyURL(proxyURL))
+	}
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("has no host, got %q", redactProxyURL(proxyURL))
+	}
+	if strings.Trim(proxyURL.Path, "/") != "" || proxyURL.RawQuery != "" {
+		return nil, fmt.Errorf("cannot have a path or a query, got %q", redactProxyURL(proxyURL))
+	}
+	return proxyURL, nil
+}
+
+// redactProxyURL returns the URL with its password masked.
+func redactProxyURL(proxyURL *url.URL) string {
+	if _, ok := proxyURL.User.Password(); !ok {
+		return proxyURL.String()
+	}
+	return strings.Replace(proxyURL.Redacted(), ":xxxxx@", ":"+redactedValue+"@", 1)
+}
+
+// apply sets the proxy variables of the process, inherited by every command
+// run and used by the native client.
+func (p proxySettings) apply() {
+	for _, variable := range []struct{ name, value string }{
+		{httpProxy, p.httpProxy},
+		{httpsProxy, p.httpsProxy},
+		{noProxy, p.noProxy},
+	} {
+		if variable.value == "" {
+			continue
+		}
+		if err := os.Setenv(variable.name, variable.value); err != nil {
+			logrus.Printf("Failed to set %s with error %v\n", variable.name, err)
+			continue
+		}
+		logged := variable.value
+		if proxyURL, err := url.Parse(variable.value); err == nil && variable.name != noProxy {
+			logged = redactProxyURL(proxyURL)
+		}
+		logrus.Printf("Using %s=%s\n", variable.name, logged)
+	}
+}
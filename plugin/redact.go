@@ -0,0 +1,178 @@
+package plugin
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redactedValue = "******"
+
+// maxRedactLineLength bounds how much output a redactWriter holds back while
+// waiting for the end of a line.
+const maxRedactLineLength = 64 * 1024
+
+// minRedactedLineLength is the shortest line of a multi-line secret masked
+// on its own.
+const minRedactedLineLength = 16
+
+// minRedactedSecretLength is the shortest secret masked, shorter values
+// would mask unrelated output.
+const minRedactedSecretLength = 4
+
+// redactor masks the credential values of a step.
+type redactor struct {
+	secrets []string
+	// tooShort counts the secrets not masked for being too short.
+	tooShort int
+}
+
+// newRedactor returns a redactor masking Password, APIKey, AccessToken,
+// PEMFileContents, ProxyPassword, the OIDC identity token and the
+// credentials of the additional servers. The lines of multi-line values are
+// masked on their own too, as tools often print them line by line. Secrets
+// shorter than minRedactedSecretLength are not masked.
+func newRedactor(args Args) *redactor {
+	seen := map[string]bool{}
+	r := &redactor{}
+	add := func(secret string) {
+		if secret == "" || seen[secret] {
+			return
+		}
+		seen[secret] = true
+		if len(secret) < minRedactedSecretLength {
+			r.tooShort++
+			return
+		}
+		r.secrets = append(r.secrets, secret)
+	}
+
+	secrets := []string{args.Password, args.APIKey, args.AccessToken, args.PEMFileContents, args.ProxyPassword,
+		lookupEnv(args.OIDCTokenEnv), args.oidcIdentityToken}
+	servers, _ := parseServers(args.Servers)
+	for _, server := range servers {
+		secrets = append(secrets, server.secrets()...)
+	}
+
+	for _, secret := range secrets {
+		add(secret)
+		if !strings.Contains(strings.TrimSpace(secret), "\n") {
+			continue
+		}
+		for _, line := range strings.Split(secret, "\n") {
+			line = strings.TrimSpace(line)
+			// the PEM armor lines and short trailing lines are not secret and
+			// would mask unrelated output
+			if len(line) >= minRedactedLineLength && !strings.HasPrefix(line, "-----") {
+				add(line)
+			}
+		}
+	}
+
+	// mask the longest values first so a secret containing another one is
+	// masked entirely
+	sort.Slice(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+	return r
+}
+
+func (r *redactor) redact(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	return s
+}
+
+// redactWriter masks secrets in the output written to it. Output is passed on
+// line by line so a secret split across writes is still masked, Flush writes
+// the last incomplete line.
+type redactWriter struct {
+	w        io.Writer
+	redactor *redactor
+	buf      []byte
+}
+
+func newRedactWriter(w io.Writer, r *redactor) *redactWriter {
+	return &redactWriter{w: w, redactor: r}
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	end := bytes.LastIndexAny(w.buf, "\r\n") + 1
+	if end == 0 && len(w.buf) > maxRedactLineLength {
+		end = len(w.buf)
+	}
+	if end > 0 {
+		if _, err := io.WriteString(w.w, w.redactor.redact(string(w.buf[:end]))); err != nil {
+			return 0, err
+		}
+		w.buf = append(w.buf[:0], w.buf[end:]...)
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered output.
+func (w *redactWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.w, w.redactor.redact(string(w.buf)))
+	w.buf = w.buf[:0]
+	return err
+}
+
+// logRedactionHook masks secrets in the logrus messages and fields.
+type logRedactionHook struct {
+	mu       sync.RWMutex
+	redactor *redactor
+}
+
+var (
+	logRedaction     = &logRedactionHook{}
+	logRedactionOnce sync.Once
+)
+
+// redactLogs masks the credentials of args in everything logged with logrus.
+func redactLogs(args Args) {
+	logRedactionOnce.Do(func() {
+		logrus.AddHook(logRedaction)
+	})
+	r := newRedactor(args)
+	logRedaction.mu.Lock()
+	logRedaction.redactor = r
+	logRedaction.mu.Unlock()
+	if r.tooShort > 0 {
+		logrus.Printf("Warning: %d secret(s) shorter than %d characters are not masked in the logs\n",
+			r.tooShort, minRedactedSecretLength)
+	}
+}
+
+func (h *logRedactionHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *logRedactionHook) Fire(entry *logrus.Entry) error {
+	h.mu.RLock()
+	r := h.redactor
+	h.mu.RUnlock()
+
+	entry.Message = r.redact(entry.Message)
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = r.redact(v)
+		case error:
+			entry.Data[key] = r.redact(v.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Result describes what a step did. It is written to PLUGIN_RESULT_FILE and
+// DRONE_OUTPUT for the following steps.
+type Result struct {
+	Status       string            `json:"status"`
+	Error        string            `json:"error,omitempty"`
+	BuildName    string            `json:"build_name,omitempty"`
+	BuildNumber  string            `json:"build_number,omitempty"`
+	BuildInfoURL string            `json:"build_info_url,omitempty"`
+	Commands     []CommandResult   `json:"commands"`
+	Artifacts    []ArtifactDetails `json:"artifacts"`
+	Servers      []ServerResult    `json:"servers,omitempty"`
+}
+
+// CommandResult describes a single command run by the step.
+type CommandResult struct {
+	Command    string `json:"command"`
+	DurationMs int64  `json:"duration_ms"`
+	ExitCode   int    `json:"exit_code"`
+}
+
+// resultRecorder collects the Result of a step, a nil recorder ignores
+// everything recorded.
+type resultRecorder struct {
+	mu     sync.Mutex
+	result Result
+}
+
+func newResultRecorder() *resultRecorder {
+	return &resultRecorder{result: Result{Commands: []CommandResult{}, Artifacts: []ArtifactDetails{}}}
+}
+
+func (r *resultRecorder) addCommand(command string, duration time.Duration, exitCode int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Commands = append(r.result.Commands, CommandResult{
+		Command: command, DurationMs: duration.Milliseconds(), ExitCode: exitCode,
+	})
+}
+
+func (r *resultRecorder) addArtifacts(artifacts []ArtifactDetails) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Artifacts = append(r.result.Artifacts, artifacts...)
+}
+
+func (r *resultRecorder) addServer(server ServerResult) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.Servers = append(r.result.Servers, server)
+}
+
+func (r *resultRecorder) setBuildInfoURL(url string) {
+	if r == nil || url == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result.BuildInfoURL = url
+}
+
+// recordOutput records the artifacts of a jf --detailed-summary and the
+// build-info URL printed by jf rt build-publish.
+func (r *resultRecorder) recordOutput(cmdArgs []string, out []byte) {
+	if r == nil {
+		return
+	}
+	artifacts, buildInfoURL := parseCommandOutput(out, isDownloadCommand(cmdArgs))
+	r.addArtifacts(artifacts)
+	r.setBuildInfoURL(buildInfoURL)
+}
+
+// finish returns the recorded Result with the outcome of the step.
+func (r *resultRecorder) finish(args Args, err error) Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := r.result
+	result.BuildName = args.BuildName
+	result.BuildNumber = args.BuildNumber
+	result.Status = ResultSuccess
+	if err != nil {
+		result.Status = ResultFailure
+		result.Error = newRedactor(args).redact(err.Error())
+	}
+	return result
+}
+
+// wantsResult reports whether the step result is written anywhere.
+func wantsResult(args Args) bool {
+	return args.ResultFile != "" || args.OutputFile != ""
+}
+
+// capturesOutput reports whether the output of the command is parsed for
+// the step result.
+func capturesOutput(cmdArgs []string) bool {
+	for _, arg := range cmdArgs {
+		switch {
+		case arg == "--detailed-summary", arg == "--detailed-summary=true":
+			return true
+		case arg == BuildPublish, arg == "bp":
+			return true
+		}
+	}
+	return false
+}
+
+func isDownloadCommand(cmdArgs []string) bool {
+	for _, arg := range cmdArgs {
+		if arg == "download" || arg == "dl" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCommandOutput finds the JSON documents printed by jf in out, reading
+// the transferred files of a detailed summary and the build-info URL.
+func parseCommandOutput(out []byte, download bool) ([]ArtifactDetails, string) {
+	var artifacts []ArtifactDetails
+	var buildInfoURL string
+
+	for offset := 0; offset < len(out); {
+		start := bytes.IndexByte(out[offset:], '{')
+		if start < 0 {
+			break
+		}
+		start += offset
+
+		var doc struct {
+			Files []struct {
+				Source string `json:"source"`
+				Target string `json:"target"`
+				Sha256 string `json:"sha256"`
+			} `json:"files"`
+			BuildInfoUiUrl string `json:"buildInfoUiUrl"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(out[start:]))
+		if err := decoder.Decode(&doc); err != nil {
+			offset = start + 1
+			continue
+		}
+		offset = start + int(decoder.InputOffset())
+
+		for _, file := range doc.Files {
+			artifact := ArtifactDetails{LocalPath: file.Source, RemotePath: file.Target, Sha256: file.Sha256}
+			if download {
+				artifact.LocalPath, artifact.RemotePath = file.Target, file.Source
+			}
+			artifacts = append(artifacts, artifact)
+		}
+		if doc.BuildInfoUiUrl != "" {
+			buildInfoURL = doc.BuildInfoUiUrl
+		}
+	}
+	return artifacts, buildInfoURL
+}
+
+// writeResult writes the result as JSON to PLUGIN_RESULT_FILE and as
+// RESULT, BUILD_NAME, BUILD_NUMBER and BUILD_INFO_URL to DRONE_OUTPUT.
+func writeResult(args Args, result Result) error {
+	if args.ResultFile != "" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding result: %s", err)
+		}
+		if dir := filepath.Dir(args.ResultFile); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("error creating result folder: %s", err)
+			}
+		}
+		if err := os.WriteFile(args.ResultFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("error writing result file: %s", err)
+		}
+	}
+
+	if args.OutputFile != "" {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("error encoding result: %s", err)
+		}
+		var output strings.Builder
+		fmt.Fprintf(&output, "RESULT=%s\n", data)
+		fmt.Fprintf(&output, "BUILD_NAME=%s\n", result.BuildName)
+		fmt.Fprintf(&output, "BUILD_NUMBER=%s\n", result.BuildNumber)
+		fmt.Fprintf(&output, "BUILD_INFO_URL=%s\n", result.BuildInfoURL)
+
+		f, err := os.OpenFile(args.OutputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("error opening output file: %s", err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(output.String()); err != nil {
+			return fmt.Errorf("error writing output file: %s", err)
+		}
+	}
+	return nil
+}
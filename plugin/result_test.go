@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommandOutput(t *testing.T) {
+	out := []byte(`[Info] Uploading artifacts...
+{
+  "status": "success",
+  "totals": {"success": 1, "failure": 0},
+  "files": [{"source": "dist/a.txt", "target": "repo/a.txt", "sha256": "abc123"}]
+}
+[Info] Done {not json}
+{"buildInfoUiUrl": "https://artifactory.test.io/ui/builds/t2/v1.0"}
+`)
+
+	artifacts, buildInfoURL := parseCommandOutput(out, false)
+	want := []ArtifactDetails{{LocalPath: "dist/a.txt", RemotePath: "repo/a.txt", Sha256: "abc123"}}
+	if !reflect.DeepEqual(artifacts, want) {
+		t.Errorf("Expected: %+v, Got: %+v", want, artifacts)
+	}
+	if buildInfoURL != "https://artifactory.test.io/ui/builds/t2/v1.0" {
+		t.Errorf("Unexpected build info url: %s", buildInfoURL)
+	}
+
+	artifacts, _ = parseCommandOutput(out, true)
+	want = []ArtifactDetails{{LocalPath: "repo/a.txt", RemotePath: "dist/a.txt", Sha256: "abc123"}}
+	if !reflect.DeepEqual(artifacts, want) {
+		t.Errorf("Expected: %+v, Got: %+v", want, artifacts)
+	}
+}
+
+func TestExecWritesResult(t *testing.T) {
+	dir := t.TempDir()
+	fakeJf := `#!/bin/sh
+case "$1 $2" in
+"rt u")
+  echo '{"status":"success","files":[{"source":"a.txt","target":"repo/a.txt","sha256":"abc123"}]}' ;;
+"rt build-publish")
+  echo '{"buildInfoUiUrl":"https://artifactory.test.io/ui/builds/t2/v1.0"}' ;;
+esac
+`
+	installFakeJf(t, fakeJf)
+
+	args := Args{
+		URL:              RtUrlTestStr,
+		AccessToken:      RtAccessToken,
+		Source:           "a.txt",
+		Target:           "repo/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+		ResultFile:       filepath.Join(dir, "out", "result.json"),
+		OutputFile:       filepath.Join(dir, "drone-output.env"),
+	}
+	if _, err := captureStdout(t, func() error { return Exec(context.Background(), args) }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(args.ResultFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Invalid result file: %v", err)
+	}
+
+	if result.Status != ResultSuccess || result.BuildName != RtBuildName || result.BuildNumber != RtBuildNumber {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.BuildInfoURL != "https://artifactory.test.io/ui/builds/t2/v1.0" {
+		t.Errorf("Unexpected build info url: %s", result.BuildInfoURL)
+	}
+	wantArtifacts := []ArtifactDetails{{LocalPath: "a.txt", RemotePath: "repo/a.txt", Sha256: "abc123"}}
+	if !reflect.DeepEqual(result.Artifacts, wantArtifacts) {
+		t.Errorf("Expected: %+v, Got: %+v", wantArtifacts, result.Artifacts)
+	}
+	if len(result.Commands) != 4 {
+		t.Fatalf("Expected 4 commands, Got: %+v", result.Commands)
+	}
+	for _, cmd := range result.Commands {
+		if cmd.ExitCode != 0 || strings.Contains(cmd.Command, RtAccessToken) {
+			t.Errorf("Unexpected command result: %+v", cmd)
+		}
+	}
+	if !strings.Contains(result.Commands[1].Command, "--detailed-summary=true") {
+		t.Errorf("Expected the upload to print a detailed summary, Got: %s", result.Commands[1].Command)
+	}
+
+	output, err := os.ReadFile(args.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output), "BUILD_INFO_URL=https://artifactory.test.io/ui/builds/t2/v1.0\n") ||
+		!strings.Contains(string(output), `RESULT={"status":"success"`) {
+		t.Errorf("Unexpected output file: %s", output)
+	}
+}
+
+func TestExecWritesFailedResult(t *testing.T) {
+	resultFile := filepath.Join(t.TempDir(), "result.json")
+	err := Exec(context.Background(), Args{Command: "unknown", ResultFile: resultFile})
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+
+	data, readErr := os.ReadFile(resultFile)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Invalid result file: %v", err)
+	}
+	if result.Status != ResultFailure || result.Error != err.Error() {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
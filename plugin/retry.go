@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os/exec"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryBackoff    = time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+
+	// retryOutputLimit bounds the stderr kept to classify a failure.
+	retryOutputLimit = 64 * 1024
+)
+
+// permanentFailurePatterns match failures retrying cannot fix, they take
+// precedence over transientFailurePatterns.
+var permanentFailurePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(401|403)\b`),
+	regexp.MustCompile(`(?i)\b(unauthorized|forbidden)\b`),
+	regexp.MustCompile(`(?i)(bad|invalid|wrong) (credentials|username|password|token|api key)`),
+}
+
+// transientFailurePatterns match the failures of a busy or unreachable
+// server: 5xx and 429 responses, reset and refused connections and timeouts.
+var transientFailurePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(status|code|response)[^0-9\n]{0,20}\b(5\d\d|429)\b`),
+	regexp.MustCompile(`\b(5\d\d|429) (Internal Server Error|Bad Gateway|Service Unavailable|Gateway Timeout|Too Many Requests)`),
+	regexp.MustCompile(`(?i)connection (reset|refused)`),
+	regexp.MustCompile(`(?i)(i/o timeout|tls handshake timeout|broken pipe|unexpected EOF)`),
+	regexp.MustCompile(`(?i)(no such host|temporary failure in name resolution|server misbehaving)`),
+}
+
+// retryableCommands lists the jf commands safe to run again, by command
+// path: the transfers, overwriting the same files, the queries and the
+// server and build tool configs, overwritten as well. Build tool runs and the
+// commands changing state, such as build-promote, build-add-dependencies and
+// delete, are run once.
+var retryableCommands = map[string]bool{
+	"rt u": true, "rt upload": true,
+	"rt dl": true, "rt download": true,
+	"rt s": true, "rt search": true,
+	"rt bp": true, "rt build-publish": true,
+	"rt bs": true, "rt build-scan": true, "build-scan": true,
+	"rt ping":    true,
+	"config add": true,
+	MvnConfig:    true,
+	GradleConfig: true,
+}
+
+// isRetryableCommand reports whether the jf command may be retried, see
+// retryableCommands.
+func isRetryableCommand(cmdArgs []string) bool {
+	if len(cmdArgs) < 2 {
+		return false
+	}
+	path := cmdArgs[1]
+	if (path == "rt" || path == "config") && len(cmdArgs) > 2 {
+		path += " " + cmdArgs[2]
+	}
+	return retryableCommands[path]
+}
+
+// retryPolicy retries failed commands with an exponential backoff and
+// jitter, see isTransientFailure.
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+func newRetryPolicy(args Args) retryPolicy {
+	policy := retryPolicy{
+		maxAttempts: args.RetryMaxAttempts,
+		backoff:     args.RetryBackoff,
+		maxBackoff:  args.RetryMaxBackoff,
+	}
+	if policy.maxAttempts < 1 {
+		policy.maxAttempts = 1
+	}
+	if policy.backoff <= 0 {
+		policy.backoff = defaultRetryBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultRetryMaxBackoff
+	}
+	return policy
+}
+
+var (
+	jitterMu   sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// delay returns the wait before the attempt following the given one: the
+// backoff doubled after every attempt, capped, of which a random half is
+// waited so concurrent steps do not retry in lockstep.
+func (p retryPolicy) delay(attempt int) time.Duration {
+	delay := p.backoff
+	for i := 1; i < attempt && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return delay/2 + time.Duration(jitterRand.Int63n(int64(delay/2)+1))
+}
+
+// isTransientFailure reports whether the failed command may succeed when
+// run again, judging by its exit and its stderr. Commands killed, timed out
+// or not started are not retried, nor authentication failures.
+func isTransientFailure(err error, stderr []byte) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() < 0 {
+		return false
+	}
+	for _, pattern := range permanentFailurePatterns {
+		if pattern.Match(stderr) {
+			return false
+		}
+	}
+	for _, pattern := range transientFailurePatterns {
+		if pattern.Match(stderr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRetry returns the problems of the retry settings.
+func checkRetry(args Args) []string {
+	var problems []string
+	if args.RetryMaxAttempts < 0 {
+		problems = append(problems, fmt.Sprintf("PLUGIN_RETRY_MAX_ATTEMPTS cannot be negative, got %d", args.RetryMaxAttempts))
+	}
+	if args.RetryBackoff < 0 || args.RetryMaxBackoff < 0 {
+		problems = append(problems, "PLUGIN_RETRY_BACKOFF and PLUGIN_RETRY_MAX_BACKOFF cannot be negative")
+	}
+	return problems
+}
+
+// tailWriter keeps the last bytes written to it.
+type tailWriter struct {
+	buf   []byte
+	limit int
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.limit {
+		w.buf = w.buf[len(w.buf)-w.limit:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsTransientFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the failing command is a shell builtin")
+	}
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+
+	tests := []struct {
+		err    error
+		stderr string
+		want   bool
+	}{
+		{exitErr, "[Error] server response: 502 Bad Gateway", true},
+		{exitErr, "[Error] received status code 503", true},
+		{exitErr, "[Error] 429 Too Many Requests", true},
+		{exitErr, "read tcp 10.0.0.1:443: connection reset by peer", true},
+		{exitErr, "[Error] server response: 401 Unauthorized", false},
+		{exitErr, "[Error] 403 Forbidden, bad credentials", false},
+		{exitErr, "[Error] path does not exist", false},
+		{errors.New("502 Bad Gateway"), "502 Bad Gateway", false},
+	}
+	for _, tt := range tests {
+		if got := isTransientFailure(tt.err, []byte(tt.stderr)); got != tt.want {
+			t.Errorf("%q: Expected: %v, Got: %v", tt.stderr, tt.want, got)
+		}
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := newRetryPolicy(Args{RetryBackoff: time.Second, RetryMaxBackoff: 5 * time.Second})
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		got := policy.delay(attempt + 1)
+		if got < want/2 || got > want {
+			t.Errorf("attempt %d: Expected a delay between %s and %s, Got: %s", attempt+1, want/2, want, got)
+		}
+	}
+}
+
+func TestRunCommandRetries(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	fakeJf := `#!/bin/sh
+echo "$1" >> ` + calls + `
+[ "$(wc -l < ` + calls + `)" -ge 3 ] && exit 0
+echo "$STDERR_MESSAGE" >&2
+exit 1
+`
+	installFakeJf(t, fakeJf)
+
+	args := Args{RetryMaxAttempts: 3, RetryBackoff: time.Millisecond}
+	for _, tc := range []struct {
+		cmdArgs   []string
+		stderr    string
+		wantErr   bool
+		wantCalls int
+	}{
+		{[]string{"jf", "rt", "ping"}, "[Error] server response: 502 Bad Gateway", false, 3},
+		{[]string{"jf", "rt", "ping"}, "[Error] server response: 401 Unauthorized", true, 1},
+		{[]string{"jf", "mvn", "install"}, "[Error] server response: 502 Bad Gateway", true, 1},
+		{[]string{"jf", "rt", "build-promote", "t2", "v1.0"}, "[Error] server response: 502 Bad Gateway", true, 1},
+		{[]string{"jf", "build-scan", "t2", "v1.0"}, "[Error] server response: 502 Bad Gateway", false, 3},
+		{[]string{"jf", "config", "add", "tmpServerId"}, "[Error] server response: 502 Bad Gateway", false, 3},
+		{[]string{"jf", "mvn-config", "--server-id-resolve=tmpServerId"}, "[Error] server response: 502 Bad Gateway", false, 3},
+		{[]string{"jf", "config", "remove", "tmpServerId"}, "[Error] server response: 502 Bad Gateway", true, 1},
+	} {
+		os.Remove(calls)
+		t.Setenv("STDERR_MESSAGE", tc.stderr)
+
+		err := runCommand(context.Background(), args, tc.cmdArgs, os.Stdout)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q %q: Unexpected error: %v", tc.cmdArgs, tc.stderr, err)
+		}
+		got, _ := os.ReadFile(calls)
+		if n := strings.Count(string(got), "\n"); n != tc.wantCalls {
+			t.Errorf("%q %q: Expected %d calls, Got: %d", tc.cmdArgs, tc.stderr, tc.wantCalls, n)
+		}
+	}
+}
+
+func TestCheckRetry(t *testing.T) {
+	tests := []struct {
+		args Args
+		want string
+	}{
+		{Args{RetryMaxAttempts: 3, RetryBackoff: time.Second}, ""},
+		{Args{RetryMaxAttempts: -1}, "PLUGIN_RETRY_MAX_ATTEMPTS cannot be negative, got -1"},
+		{Args{RetryMaxBackoff: -time.Second}, "PLUGIN_RETRY_BACKOFF and PLUGIN_RETRY_MAX_BACKOFF cannot be negative"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(checkRetry(tt.args), "; "); got != tt.want {
+			t.Errorf("Expected: %s, Got: %s", tt.want, got)
+		}
+	}
+}
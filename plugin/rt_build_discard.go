@@ -4,6 +4,19 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	// build-discard as a command is used only by the standalone build-discard step
+	RegisterRtCommand("", "build-discard", rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_BUILD_NAME"},
+			flagMaps: [][]JsonTagToExeFlagMapStringItem{BuildDiscardCmdJsonTagToExeFlagMapStringItemList},
+		},
+		commands:     GetBuildDiscardCommandArgs,
+		postCommands: postCommandsHandling(postActionBuildDiscard),
+	})
+}
+
 var BuildDiscardCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
 	{"--async=", "PLUGIN_ASYNC", false, false},
 	{"--delete-artifacts=", "PLUGIN_DELETE_ARTIFACTS", false, false},
@@ -13,7 +26,7 @@ var BuildDiscardCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStri
 }
 
 func GetBuildDiscardCommandArgs(args Args) ([][]string, error) {
-	bdiServerId := tmpServerId + "bdi"
+	bdiServerId := stepServerId(args, "bdi")
 	var cmdList [][]string
 
 	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(bdiServerId,
@@ -29,6 +42,8 @@ func GetBuildDiscardCommandArgs(args Args) ([][]string, error) {
 		logrus.Println("Error in GetBuildDiscardCommand ", err)
 		return cmdList, err
 	}
+	// discard on the server just configured, not on the default one
+	buildDiscardCmd = append([]string{"rt", "build-discard", "--server-id=" + bdiServerId}, buildDiscardCmd[2:]...)
 	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, buildDiscardCmd)
 	return cmdList, nil
@@ -0,0 +1,207 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	ClientJf     = "jf"
+	ClientNative = "native"
+)
+
+// ArtifactDetails describes a single artifact transferred to or from Artifactory.
+type ArtifactDetails struct {
+	LocalPath  string `json:"local_path,omitempty"`
+	RemotePath string `json:"remote_path"`
+	Sha1       string `json:"sha1,omitempty"`
+	Sha256     string `json:"sha256,omitempty"`
+	Md5        string `json:"md5,omitempty"`
+	Size       int64  `json:"size,omitempty"`
+}
+
+// ArtifactoryClient is the set of Artifactory operations the plugin relies on.
+// It is implemented by the jf CLI backend and by a native REST client, selected
+// with PLUGIN_CLIENT.
+type ArtifactoryClient interface {
+	// Upload uploads the local files matching source to target. Props use the
+	// jf notation key1=value1;key2=value2.
+	Upload(ctx context.Context, source, target, props string) ([]ArtifactDetails, error)
+
+	// Download downloads the remote files matching pattern to the local target.
+	Download(ctx context.Context, pattern, target string) ([]ArtifactDetails, error)
+
+	// SetProperties sets props on the remote path.
+	SetProperties(ctx context.Context, path, props string) error
+
+	// Search returns the remote files matching pattern.
+	Search(ctx context.Context, pattern string) ([]ArtifactDetails, error)
+
+	// PublishBuildInfo publishes build-info for the given build, referencing
+	// the artifacts when the backend does not track them itself.
+	PublishBuildInfo(ctx context.Context, buildName, buildNumber string, artifacts []ArtifactDetails) error
+}
+
+func newArtifactoryClient(args Args) (ArtifactoryClient, error) {
+	switch args.Client {
+	case "", ClientJf:
+		return &jfClient{args: args}, nil
+	case ClientNative:
+		return newRestClient(args)
+	default:
+		return nil, fmt.Errorf("unsupported client %q, supported clients are %q and %q",
+			args.Client, ClientJf, ClientNative)
+	}
+}
+
+// jfClient implements ArtifactoryClient by running the jf binary.
+type jfClient struct {
+	args Args
+
+	// serverId is set once the server config has been added.
+	serverId string
+}
+
+func (c *jfClient) Upload(ctx context.Context, source, target, props string) ([]ArtifactDetails, error) {
+	serverId, err := c.serverConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cmdArgs := getUploadCommandArgs(c.args, serverId)
+	if err := PopulateArgs(&cmdArgs, &c.args, UploadCmdJsonTagToExeFlagMapStringItemList); err != nil {
+		return nil, err
+	}
+	if props != "" {
+		cmdArgs = append(cmdArgs, "--target-props="+props)
+	}
+	cmdArgs = append(cmdArgs, source, target)
+	return c.runUpload(ctx, cmdArgs)
+}
+
+// UploadSpec uploads the files described by the spec file, props are set on
+// every uploaded file.
+func (c *jfClient) UploadSpec(ctx context.Context, spec, specVars, props string) ([]ArtifactDetails, error) {
+	serverId, err := c.serverConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cmdArgs := getUploadCommandArgs(c.args, serverId)
+	cmdArgs = append(cmdArgs, "--spec="+spec)
+	if specVars != "" {
+		cmdArgs = append(cmdArgs, "--spec-vars="+specVars)
+	}
+	if props != "" {
+		cmdArgs = append(cmdArgs, "--target-props="+props)
+	}
+	return c.runUpload(ctx, cmdArgs)
+}
+
+// runUpload runs the upload command, returning the uploaded files listed by
+// the detailed summary when the checksums are verified.
+func (c *jfClient) runUpload(ctx context.Context, cmdArgs []string) ([]ArtifactDetails, error) {
+	if !c.args.VerifyChecksums {
+		return nil, runCommand(ctx, c.args, cmdArgs, os.Stdout)
+	}
+	var out bytes.Buffer
+	if err := runCommand(ctx, c.args, cmdArgs, io.MultiWriter(os.Stdout, &out)); err != nil {
+		return nil, err
+	}
+	artifacts, _ := parseCommandOutput(out.Bytes(), false)
+	return artifacts, nil
+}
+
+func (c *jfClient) Download(ctx context.Context, pattern, target string) ([]ArtifactDetails, error) {
+	cmdArgs, err := c.baseCommandArgs(ctx, "dl")
+	if err != nil {
+		return nil, err
+	}
+	cmdArgs = append(cmdArgs, pattern, target)
+	return nil, runCommand(ctx, c.args, cmdArgs, os.Stdout)
+}
+
+func (c *jfClient) SetProperties(ctx context.Context, path, props string) error {
+	cmdArgs, err := c.baseCommandArgs(ctx, "sp")
+	if err != nil {
+		return err
+	}
+	cmdArgs = append(cmdArgs, path, props)
+	return runCommand(ctx, c.args, cmdArgs, os.Stdout)
+}
+
+func (c *jfClient) Search(ctx context.Context, pattern string) ([]ArtifactDetails, error) {
+	cmdArgs, err := c.baseCommandArgs(ctx, "s")
+	if err != nil {
+		return nil, err
+	}
+	cmdArgs = append(cmdArgs, pattern)
+
+	var out bytes.Buffer
+	if err := runCommand(ctx, c.args, cmdArgs, &out); err != nil {
+		return nil, err
+	}
+	if c.args.DryRun {
+		return nil, nil
+	}
+
+	var results []struct {
+		Path   string `json:"path"`
+		Size   int64  `json:"size"`
+		Sha1   string `json:"sha1"`
+		Sha256 string `json:"sha256"`
+		Md5    string `json:"md5"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		return nil, fmt.Errorf("error parsing search results: %s", err)
+	}
+
+	artifacts := make([]ArtifactDetails, 0, len(results))
+	for _, r := range results {
+		artifacts = append(artifacts, ArtifactDetails{
+			RemotePath: r.Path, Sha1: r.Sha1, Sha256: r.Sha256, Md5: r.Md5, Size: r.Size,
+		})
+	}
+	return artifacts, nil
+}
+
+func (c *jfClient) PublishBuildInfo(ctx context.Context, buildName, buildNumber string, artifacts []ArtifactDetails) error {
+	args := c.args
+	args.BuildName = buildName
+	args.BuildNumber = buildNumber
+	return publishBuildInfo(ctx, args)
+}
+
+// serverConfig adds the jf server config holding the credentials, once.
+func (c *jfClient) serverConfig(ctx context.Context) (string, error) {
+	if c.serverId != "" {
+		return c.serverId, nil
+	}
+	serverId := stepServerId(c.args, "")
+	configCmdArgs, err := GetConfigAddConfigCommandArgs(serverId, c.args.Username, c.args.Password,
+		c.args.URL, c.args.AccessToken, c.args.APIKey)
+	if err != nil {
+		return "", err
+	}
+	configCmdArgs = append([]string{getJfrogBin()}, configCmdArgs...)
+	if err := runCommand(ctx, c.args, configCmdArgs, os.Stdout); err != nil {
+		return "", err
+	}
+	c.serverId = serverId
+	return c.serverId, nil
+}
+
+// baseCommandArgs returns the jf rt sub command with url, server and tls flags.
+func (c *jfClient) baseCommandArgs(ctx context.Context, subCommand string) ([]string, error) {
+	serverId, err := c.serverConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cmdArgs := []string{getJfrogBin(), "rt", subCommand, "--url=" + c.args.URL, "--server-id=" + serverId}
+	if parseBoolOrDefault(false, c.args.Insecure) {
+		cmdArgs = append(cmdArgs, "--insecure-tls")
+	}
+	return cmdArgs, nil
+}
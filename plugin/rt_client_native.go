@@ -0,0 +1,487 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// restClient implements ArtifactoryClient on top of the Artifactory REST API,
+// so no jf binary is required.
+type restClient struct {
+	baseURL     string
+	username    string
+	password    string
+	accessToken string
+	project     string
+	flat        bool
+	dryRun      bool
+	httpClient  *http.Client
+}
+
+func newRestClient(args Args) (*restClient, error) {
+	if args.URL == "" {
+		return nil, fmt.Errorf("JFrog Artifactory URL must be set, or anonymous access is not permitted")
+	}
+	if _, err := setAuthParams([]string{}, args); err != nil {
+		return nil, err
+	}
+
+	httpClient, err := newHTTPClient(args)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &restClient{
+		baseURL:    strings.TrimSuffix(args.URL, "/"),
+		project:    args.Project,
+		flat:       parseBoolOrDefault(false, args.Flat),
+		dryRun:     args.DryRun,
+		httpClient: httpClient,
+	}
+	// keep the same precedence as setAuthParams
+	switch {
+	case args.Username != "" && args.Password != "":
+		c.username, c.password = args.Username, args.Password
+	case args.Username != "" && args.APIKey != "":
+		// the api key is the password of the user, as for jf
+		c.username, c.password = args.Username, args.APIKey
+	default:
+		c.accessToken = args.AccessToken
+	}
+	return c, nil
+}
+
+// newHTTPClient returns an http client honoring the insecure and pem file
+// settings.
+func newHTTPClient(args Args) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig := &tls.Config{}
+	if parseBoolOrDefault(false, args.Insecure) {
+		tlsConfig.InsecureSkipVerify = true
+	} else if args.PEMFileContents != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(args.PEMFileContents)) {
+			return nil, fmt.Errorf("error parsing pem file contents")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+func (c *restClient) Upload(ctx context.Context, source, target, props string) ([]ArtifactDetails, error) {
+	files, err := filepath.Glob(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source pattern %q: %s", source, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match source %q", source)
+	}
+
+	var artifacts []ArtifactDetails
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return artifacts, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		artifact, err := c.uploadFile(ctx, file, remoteUploadPath(file, target, c.flat), props)
+		if err != nil {
+			return artifacts, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+func (c *restClient) uploadFile(ctx context.Context, file, remotePath, props string) (ArtifactDetails, error) {
+	artifact, err := fileChecksums(file)
+	if err != nil {
+		return artifact, err
+	}
+	artifact.RemotePath = remotePath
+
+	f, err := os.Open(file)
+	if err != nil {
+		return artifact, err
+	}
+	defer f.Close()
+
+	req, err := c.newRequest(ctx, http.MethodPut, c.itemURL(remotePath)+matrixParams(props), f)
+	if err != nil {
+		return artifact, err
+	}
+	req.ContentLength = artifact.Size
+	req.Header.Set("X-Checksum-Sha1", artifact.Sha1)
+	req.Header.Set("X-Checksum-Sha256", artifact.Sha256)
+	req.Header.Set("X-Checksum", artifact.Md5)
+
+	logrus.Printf("Uploading %s to %s\n", file, remotePath)
+	if err := c.do(req, nil); err != nil {
+		return artifact, fmt.Errorf("error uploading %s: %s", file, err)
+	}
+	return artifact, nil
+}
+
+func (c *restClient) Download(ctx context.Context, pattern, target string) ([]ArtifactDetails, error) {
+	if !strings.ContainsAny(pattern, "*?") {
+		artifact, err := c.downloadFile(ctx, pattern, localDownloadPath(pattern, target, false))
+		if err != nil {
+			return nil, err
+		}
+		return []ArtifactDetails{artifact}, nil
+	}
+
+	found, err := c.Search(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []ArtifactDetails
+	for _, item := range found {
+		artifact, err := c.downloadFile(ctx, item.RemotePath, localDownloadPath(item.RemotePath, target, true))
+		if err != nil {
+			return artifacts, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+func (c *restClient) downloadFile(ctx context.Context, remotePath, localPath string) (ArtifactDetails, error) {
+	artifact := ArtifactDetails{RemotePath: remotePath, LocalPath: localPath}
+
+	req, err := c.newRequest(ctx, http.MethodGet, c.itemURL(remotePath), nil)
+	if err != nil {
+		return artifact, err
+	}
+	if c.dryRun {
+		fmt.Fprintf(os.Stdout, "+ %s %s\n", req.Method, req.URL.Redacted())
+		return artifact, nil
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return artifact, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return artifact, fmt.Errorf("error downloading %s: %s", remotePath, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return artifact, err
+	}
+	f, err := os.Create(localPath)
+	if err != nil {
+		return artifact, err
+	}
+	defer f.Close()
+
+	logrus.Printf("Downloading %s to %s\n", remotePath, localPath)
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, h), resp.Body)
+	if err != nil {
+		return artifact, fmt.Errorf("error downloading %s: %s", remotePath, err)
+	}
+	artifact.Size = size
+	artifact.Sha256 = hex.EncodeToString(h.Sum(nil))
+
+	if want := resp.Header.Get("X-Checksum-Sha256"); want != "" && want != artifact.Sha256 {
+		return artifact, fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s",
+			remotePath, want, artifact.Sha256)
+	}
+	return artifact, nil
+}
+
+func (c *restClient) SetProperties(ctx context.Context, itemPath, props string) error {
+	query := url.Values{}
+	query.Set("properties", props)
+	req, err := c.newRequest(ctx, http.MethodPut,
+		c.baseURL+"/api/storage/"+escapeItemPath(itemPath)+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("error setting properties on %s: %s", itemPath, err)
+	}
+	return nil
+}
+
+func (c *restClient) Search(ctx context.Context, pattern string) ([]ArtifactDetails, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.baseURL+"/api/search/aql",
+		strings.NewReader(searchQuery(pattern)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	var out struct {
+		Results []struct {
+			Repo   string `json:"repo"`
+			Path   string `json:"path"`
+			Name   string `json:"name"`
+			Size   int64  `json:"size"`
+			Sha1   string `json:"actual_sha1"`
+			Sha256 string `json:"sha256"`
+			Md5    string `json:"actual_md5"`
+		} `json:"results"`
+	}
+	if err := c.do(req, &out); err != nil {
+		return nil, fmt.Errorf("error searching %s: %s", pattern, err)
+	}
+
+	artifacts := make([]ArtifactDetails, 0, len(out.Results))
+	for _, r := range out.Results {
+		artifacts = append(artifacts, ArtifactDetails{
+			RemotePath: path.Join(r.Repo, r.Path, r.Name),
+			Sha1:       r.Sha1, Sha256: r.Sha256, Md5: r.Md5, Size: r.Size,
+		})
+	}
+	return artifacts, nil
+}
+
+func (c *restClient) PublishBuildInfo(ctx context.Context, buildName, buildNumber string, artifacts []ArtifactDetails) error {
+	if buildName == "" || buildNumber == "" {
+		return fmt.Errorf("both build name and build number need to be set when publishing build info")
+	}
+
+	module := buildInfoModule{ID: buildName}
+	for _, a := range artifacts {
+		module.Artifacts = append(module.Artifacts, buildInfoArtifact{
+			Name: path.Base(a.RemotePath), Path: a.RemotePath, Sha1: a.Sha1, Sha256: a.Sha256, Md5: a.Md5,
+		})
+	}
+	info := buildInfo{
+		Version: "1.0.1",
+		Name:    buildName,
+		Number:  buildNumber,
+		Started: time.Now().Format("2006-01-02T15:04:05.000-0700"),
+		Modules: []buildInfoModule{module},
+	}
+	body, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	endpoint := c.baseURL + "/api/build"
+	if c.project != "" {
+		endpoint += "?project=" + url.QueryEscape(c.project)
+	}
+	req, err := c.newRequest(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := c.do(req, nil); err != nil {
+		return fmt.Errorf("error publishing build info: %s", err)
+	}
+	return nil
+}
+
+type buildInfo struct {
+	Version string            `json:"version"`
+	Name    string            `json:"name"`
+	Number  string            `json:"number"`
+	Started string            `json:"started"`
+	Modules []buildInfoModule `json:"modules,omitempty"`
+}
+
+type buildInfoModule struct {
+	ID        string              `json:"id"`
+	Artifacts []buildInfoArtifact `json:"artifacts,omitempty"`
+}
+
+type buildInfoArtifact struct {
+	Name   string `json:"name"`
+	Path   string `json:"path,omitempty"`
+	Sha1   string `json:"sha1,omitempty"`
+	Sha256 string `json:"sha256,omitempty"`
+	Md5    string `json:"md5,omitempty"`
+}
+
+func (c *restClient) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	case c.accessToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+	return req, nil
+}
+
+// do sends the request and decodes a json response into out when out is not
+// nil. In dry run mode the request is only printed.
+func (c *restClient) do(req *http.Request, out interface{}) error {
+	if c.dryRun {
+		fmt.Fprintf(os.Stdout, "+ %s %s\n", req.Method, req.URL.Redacted())
+		return nil
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *restClient) itemURL(itemPath string) string {
+	return c.baseURL + "/" + escapeItemPath(itemPath)
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+func escapeItemPath(itemPath string) string {
+	segments := strings.Split(strings.TrimPrefix(itemPath, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// matrixParams converts jf style props into Artifactory matrix parameters,
+// see splitEscaped.
+func matrixParams(props string) string {
+	var params []string
+	for _, prop := range splitEscaped(props, ';') {
+		keyValue := strings.SplitN(prop, "=", 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			continue
+		}
+		var values []string
+		for _, value := range splitEscaped(keyValue[1], ',') {
+			values = append(values, url.PathEscape(strings.Trim(strings.TrimSpace(value), "\"'")))
+		}
+		params = append(params, url.PathEscape(strings.TrimSpace(keyValue[0]))+"="+strings.Join(values, ","))
+	}
+	sort.Strings(params)
+	if len(params) == 0 {
+		return ""
+	}
+	return ";" + strings.Join(params, ";")
+}
+
+// splitEscaped splits s on sep the way jf splits props: key1=value1;key2=value2
+// on semicolons, then every value on commas, a property holding several
+// values. A backslash escapes the separator.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == sep:
+			part.WriteByte(sep)
+			i++
+		case s[i] == sep:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(s[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// remoteUploadPath mirrors the jf target semantics: a target ending with a
+// slash is a folder, anything else is the full path of the uploaded file.
+func remoteUploadPath(file, target string, flat bool) string {
+	if !strings.HasSuffix(target, "/") {
+		return target
+	}
+	if flat {
+		return target + filepath.Base(file)
+	}
+	rel := filepath.ToSlash(strings.TrimPrefix(file, filepath.VolumeName(file)))
+	rel = strings.TrimPrefix(path.Clean("/"+rel), "/")
+	return target + rel
+}
+
+func localDownloadPath(remotePath, target string, isPattern bool) string {
+	if target == "" {
+		target = "./"
+	}
+	if !isPattern && !strings.HasSuffix(target, "/") {
+		return target
+	}
+	return filepath.Join(target, path.Base(remotePath))
+}
+
+// searchQuery builds an AQL query for a repo/path/name pattern.
+func searchQuery(pattern string) string {
+	pattern = strings.TrimPrefix(pattern, "/")
+	repo, rest, _ := strings.Cut(pattern, "/")
+	dir, name := path.Split(rest)
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" {
+		dir = "."
+	}
+	if name == "" {
+		name = "*"
+	}
+	query, _ := json.Marshal(map[string]interface{}{
+		"repo": repo,
+		"path": map[string]string{"$match": dir},
+		"name": map[string]string{"$match": name},
+		"type": "file",
+	})
+	return fmt.Sprintf(`items.find(%s).include("repo","path","name","size","actual_sha1","sha256","actual_md5")`, query)
+}
+
+func fileChecksums(file string) (ArtifactDetails, error) {
+	artifact := ArtifactDetails{LocalPath: file}
+	f, err := os.Open(file)
+	if err != nil {
+		return artifact, err
+	}
+	defer f.Close()
+
+	sha1Hash, sha256Hash, md5Hash := sha1.New(), sha256.New(), md5.New()
+	size, err := io.Copy(io.MultiWriter(sha1Hash, sha256Hash, md5Hash), f)
+	if err != nil {
+		return artifact, err
+	}
+	artifact.Size = size
+	artifact.Sha1 = hex.EncodeToString(sha1Hash.Sum(nil))
+	artifact.Sha256 = hex.EncodeToString(sha256Hash.Sum(nil))
+	artifact.Md5 = hex.EncodeToString(md5Hash.Sum(nil))
+	return artifact, nil
+}
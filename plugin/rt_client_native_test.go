@@ -0,0 +1,236 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestClientUpload(t *testing.T) {
+	var gotPath, gotAuth, gotSha256, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Expected PUT, Got: %s", r.Method)
+		}
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		gotSha256 = r.Header.Get("X-Checksum-Sha256")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := newRestClient(Args{URL: server.URL + "/artifactory/", AccessToken: RtAccessToken, Flat: "true"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	artifacts, err := client.Upload(context.Background(), file, "libs-release/app/", "vcs.revision=abc;team=a b")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantPath := "/artifactory/libs-release/app/app.txt;team=a%20b;vcs.revision=abc"
+	if gotPath != wantPath {
+		t.Errorf("Expected: %s, Got: %s", wantPath, gotPath)
+	}
+	if gotAuth != "Bearer "+RtAccessToken {
+		t.Errorf("Expected bearer auth, Got: %s", gotAuth)
+	}
+	if gotBody != "hello" {
+		t.Errorf("Expected: hello, Got: %s", gotBody)
+	}
+	wantSha256 := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if gotSha256 != wantSha256 {
+		t.Errorf("Expected: %s, Got: %s", wantSha256, gotSha256)
+	}
+	if len(artifacts) != 1 || artifacts[0].RemotePath != "libs-release/app/app.txt" || artifacts[0].Sha256 != wantSha256 {
+		t.Errorf("Unexpected artifacts: %+v", artifacts)
+	}
+}
+
+func TestMatrixParams(t *testing.T) {
+	for props, want := range map[string]string{
+		"vcs.revision=abc;team=a b":  ";team=a%20b;vcs.revision=abc",
+		"a=1,b=2":                    ";a=1,b=2",
+		"os=linux,darwin;note=x\\,y": ";note=x%2Cy;os=linux,darwin",
+		"a=x\\;y;empty;=1":           ";a=x%3By",
+	} {
+		if got := matrixParams(props); got != want {
+			t.Errorf("For %s, Expected: %s, Got: %s", props, want, got)
+		}
+	}
+}
+
+func TestRestClientDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "ab" || pass != "cd" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/artifactory/api/search/aql":
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), `"repo":"libs-release"`) {
+				t.Errorf("Unexpected query: %s", body)
+			}
+			_, _ = io.WriteString(w, `{"results":[{"repo":"libs-release","path":"app","name":"a.jar"}]}`)
+		case "/artifactory/libs-release/app/a.jar":
+			_, _ = io.WriteString(w, "jar")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := newRestClient(Args{URL: server.URL + "/artifactory", Username: "ab", Password: "cd"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	artifacts, err := client.Download(context.Background(), "libs-release/app/*.jar", dir+"/")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(artifacts) != 1 {
+		t.Fatalf("Expected 1 artifact, Got: %d", len(artifacts))
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "a.jar"))
+	if err != nil || string(content) != "jar" {
+		t.Errorf("Unexpected downloaded content: %q, err: %v", content, err)
+	}
+}
+
+func TestNativeDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/artifactory/libs-release/app/a.txt" || r.Header.Get("Authorization") != "Bearer "+RtAccessToken {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	args := Args{
+		Command:     "download",
+		Client:      ClientNative,
+		URL:         server.URL + "/artifactory/",
+		AccessToken: RtAccessToken,
+		Target:      "libs-release/app/a.txt",
+		Source:      dir + "/",
+	}
+	if err := Exec(context.Background(), args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	if err != nil || string(content) != "hello" {
+		t.Errorf("Unexpected downloaded content: %q, err: %v", content, err)
+	}
+}
+
+func TestRestClientSetProperties(t *testing.T) {
+	var gotPath, gotProps, gotUser, gotApiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotProps = r.URL.Query().Get("properties")
+		gotUser, gotApiKey, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := newRestClient(Args{URL: server.URL, Username: "ab", APIKey: "secretkey"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := client.SetProperties(context.Background(), "repo/a.jar", "a=1;b=2"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotPath != "/api/storage/repo/a.jar" || gotProps != "a=1;b=2" || gotUser != "ab" || gotApiKey != "secretkey" {
+		t.Errorf("Unexpected request path: %s props: %s user: %s api key: %s", gotPath, gotProps, gotUser, gotApiKey)
+	}
+}
+
+func TestRestClientPublishBuildInfo(t *testing.T) {
+	var got buildInfo
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/build" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := newRestClient(Args{URL: server.URL, AccessToken: RtAccessToken})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	artifacts := []ArtifactDetails{{RemotePath: "repo/app/a.jar", Sha1: "s1", Sha256: "s256", Md5: "m5"}}
+	if err := client.PublishBuildInfo(context.Background(), RtBuildName, RtBuildNumber, artifacts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Name != RtBuildName || got.Number != RtBuildNumber {
+		t.Errorf("Unexpected build: %s %s", got.Name, got.Number)
+	}
+	if len(got.Modules) != 1 || len(got.Modules[0].Artifacts) != 1 || got.Modules[0].Artifacts[0].Name != "a.jar" {
+		t.Errorf("Unexpected modules: %+v", got.Modules)
+	}
+
+	err = client.PublishBuildInfo(context.Background(), "", RtBuildNumber, nil)
+	if err == nil {
+		t.Errorf("Expected error for missing build name")
+	}
+}
+
+func TestRestClientErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "forbidden")
+	}))
+	defer server.Close()
+
+	client, err := newRestClient(Args{URL: server.URL, AccessToken: RtAccessToken})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err = client.Search(context.Background(), "repo/*.jar")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected 403 error, Got: %v", err)
+	}
+}
+
+func TestRemoteUploadPath(t *testing.T) {
+	tests := []struct {
+		file, target string
+		flat         bool
+		expected     string
+	}{
+		{"/harness/cache.txt", "repo/dir/", true, "repo/dir/cache.txt"},
+		{"/harness/cache.txt", "repo/dir/", false, "repo/dir/harness/cache.txt"},
+		{"./build/app.jar", "repo/", false, "repo/build/app.jar"},
+		{"/harness/cache.txt", "repo/dir/renamed.txt", false, "repo/dir/renamed.txt"},
+	}
+	for _, tc := range tests {
+		result := remoteUploadPath(tc.file, tc.target, tc.flat)
+		if result != tc.expected {
+			t.Errorf("For %s -> %s, Expected: %s, Got: %s", tc.file, tc.target, tc.expected, result)
+		}
+	}
+}
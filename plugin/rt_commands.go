@@ -1,14 +1,20 @@
 package plugin
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -24,9 +30,68 @@ const (
 	tmpServerId  = "tmpServerId"
 )
 
-func HandleRtCommands(args Args) error {
+// stepServerId returns the id of the server config of the given kind added
+// for args, the servers of PLUGIN_SERVERS getting their own. The id ends with
+// the suffix of the step's workspace, so steps sharing a jf home, or servers
+// sharing a name, never overwrite each other's config.
+func stepServerId(args Args, kind string) string {
+	serverId := tmpServerId + kind
+	if args.serverName != "" {
+		serverId += "-" + args.serverName
+	}
+	if suffix := args.workspace.serverIdSuffix(); suffix != "" {
+		serverId += "-" + suffix
+	}
+	return serverId
+}
+
+// resolverServerId returns the server config holding the credentials of the
+// Maven and Gradle builds.
+func resolverServerId(args Args) string {
+	if args.ResolverId == "" {
+		return stepServerId(args, "")
+	}
+	return args.ResolverId
+}
+
+// deployerServerId returns the server config holding the deployer
+// credentials of the Maven and Gradle publish.
+func deployerServerId(args Args) string {
+	if args.DeployerId == "" {
+		return stepServerId(args, "")
+	}
+	return args.DeployerId
+}
+
+// GetDeployerPublishPostCommands publishes the build-info of a Maven or
+// Gradle publish through the deployer server config, followed by the other
+// post actions.
+func GetDeployerPublishPostCommands(args Args) ([][]string, error) {
+	var cmdList [][]string
+
+	rtPublishBuildInfoCommandArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber,
+		"--server-id=" + deployerServerId(args)}
+	err := PopulateArgs(&rtPublishBuildInfoCommandArgs, &args, RtBuildInfoPublishCmdJsonTagToExeFlagMap)
+	if err != nil {
+		logrus.Println("PopulateArgs error: ", err)
+		return cmdList, err
+	}
+	cmdList = append(cmdList, rtPublishBuildInfoCommandArgs)
+
+	postCommandsList, err := lifecyclePostCommands(args, postActionBuildPublish)
+	if err != nil {
+		return cmdList, err
+	}
+	return append(cmdList, postCommandsList...), nil
+}
+
+// processWaitDelay bounds how long a killed command may keep its output
+// open before Wait returns.
+const processWaitDelay = 10 * time.Second
 
-	commandsList, err := GetRtCommandsList(args)
+func HandleRtCommands(ctx context.Context, args Args) error {
+
+	_, handler, err := resolveRtCommand(args)
 	if err != nil {
 		logrus.Println("Error Unable to get rt commands list err = ", err)
 		return err
@@ -38,19 +103,41 @@ func HandleRtCommands(args Args) error {
 		return err
 	}
 
+	postCommandsList, err := handler.PostCommands(args)
+	if err != nil {
+		logrus.Println("Error Unable to get rt post commands list err = ", err)
+		return err
+	}
+
+	if command, ok := handler.(rtCommand); ok {
+		if run := command.runner(args); run != nil {
+			return runPostCommands(ctx, args, postCommandsList, run(ctx, args))
+		}
+	}
+
+	commandsList, err := handler.Commands(args)
+	if err != nil {
+		logrus.Println("Error Unable to get rt commands list err = ", err)
+		return err
+	}
+
 	for _, cmd := range commandsList {
 		execArgs := []string{getJfrogBin()}
 		execArgs = append(execArgs, cmd...)
-		err := ExecCommand(args, execArgs)
+		err = ExecCommand(ctx, args, execArgs)
 		if err != nil {
 			logrus.Println("Error Unable to run err = ", err)
-			return err
+			break
 		}
 	}
 
-	return err
+	return runPostCommands(ctx, args, postCommandsList, err)
 }
 
+// WriteKnownGoodServerCertsForTls makes jf trust the certificate of
+// PLUGIN_PEM_FILE_CONTENTS, written to PLUGIN_PEM_FILE_PATH when set. With a
+// workspace the certificates are put in the jf home of the step, see
+// writeStepCerts.
 func WriteKnownGoodServerCertsForTls(args Args) error {
 
 	insecure := parseBoolOrDefault(false, args.Insecure)
@@ -58,150 +145,333 @@ func WriteKnownGoodServerCertsForTls(args Args) error {
 		return nil
 	}
 
+	if args.workspace != nil {
+		return writeStepCerts(args)
+	}
+
 	// create pem file
 	if args.PEMFileContents != "" {
 		var path string
 		// figure out path to write pem file
-		if args.PEMFilePath == "" {
-			if runtime.GOOS == "windows" {
-				path = "C:/users/ContainerAdministrator/.jfrog/security/certs/cert.pem"
-			} else {
-				path = "/root/.jfrog/security/certs/cert.pem"
-			}
-		} else {
+		switch {
+		case args.PEMFilePath != "":
 			path = args.PEMFilePath
+		case runtime.GOOS == "windows":
+			path = "C:/users/ContainerAdministrator/.jfrog/security/certs/cert.pem"
+		default:
+			path = "/root/.jfrog/security/certs/cert.pem"
 		}
-		logrus.Printf("Creating pem file at %q\n", path)
-		// write pen contents to path
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			// remove filename from path
-			dir := filepath.Dir(path)
-			pemFolderErr := os.MkdirAll(dir, 0700)
-			if pemFolderErr != nil {
-				return fmt.Errorf("error creating pem folder: %s", pemFolderErr)
-			}
-			// write pem contents
-			pemWriteErr := os.WriteFile(path, []byte(args.PEMFileContents), 0600)
-			if pemWriteErr != nil {
-				return fmt.Errorf("error writing pem file: %s", pemWriteErr)
-			}
-			logrus.Printf("Successfully created pem file at %q\n", path)
+		if _, err := writePEMFile(args, path); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-func GetRtCommandsList(args Args) ([][]string, error) {
-	logrus.Println("Handling rt command handleRtCommand")
-	commandsList := [][]string{}
-	var err error
-
-	logrus.Println("Checking GetRtCommandsList args.Command ", args.Command)
-
-	if args.BuildTool == MvnCmd && (args.Command == "" || args.Command == "build") {
-		logrus.Println("mvn build start")
-		commandsList, err = GetMavenBuildCommandArgs(args)
+// writeStepCerts fills the security/certs folder of the step's jf home, as
+// jf only reads the certificates of its own home: the certificates of the
+// runner's shared jf home are copied, then the PLUGIN_PEM_FILE_PATH file and
+// the PLUGIN_PEM_FILE_CONTENTS certificate as cert.pem. A PEM file the plugin
+// writes to PLUGIN_PEM_FILE_PATH is removed with the workspace, an existing
+// one is left as it is.
+func writeStepCerts(args Args) error {
+	if args.DryRun {
+		return nil
 	}
+	home, err := args.workspace.jfrogHome()
+	if err != nil {
+		return err
+	}
+	certsDir := filepath.Join(home, "security", "certs")
 
-	if args.BuildTool == MvnCmd && args.Command == "publish" {
-		commandsList, err = GetMavenPublishCommand(args)
+	if shared := sharedJfrogHome(); shared != "" {
+		entries, err := os.ReadDir(filepath.Join(shared, "security", "certs"))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error reading the shared jf certificates: %s", err)
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				if err := copyCert(filepath.Join(shared, "security", "certs", entry.Name()), certsDir); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
-	if args.BuildTool == GradleCmd && (args.Command == "" || args.Command == "build") {
-		logrus.Println("Gradle build start")
-		commandsList, err = GetGradleCommandArgs(args)
+	if args.PEMFilePath != "" {
+		if args.PEMFileContents != "" {
+			written, err := writePEMFile(args, args.PEMFilePath)
+			if err != nil {
+				return err
+			}
+			if written {
+				args.workspace.track(args.PEMFilePath)
+			}
+		}
+		if err := copyCert(args.PEMFilePath, certsDir); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 
-	if args.BuildTool == GradleCmd && args.Command == "publish" {
-		logrus.Println("Gradle publish start")
-		commandsList, err = GetGradlePublishCommand(args)
+	if args.PEMFileContents != "" {
+		if err := os.MkdirAll(certsDir, 0700); err != nil {
+			return fmt.Errorf("error creating pem folder: %s", err)
+		}
+		if err := os.WriteFile(filepath.Join(certsDir, "cert.pem"), []byte(args.PEMFileContents), 0600); err != nil {
+			return fmt.Errorf("error writing pem file: %s", err)
+		}
 	}
+	return nil
+}
 
-	if args.Command == "download" {
-		logrus.Println("download start")
-		commandsList, err = GetDownloadCommandArgs(args)
+// writePEMFile writes PLUGIN_PEM_FILE_CONTENTS to path unless the file
+// exists, and reports whether it did.
+func writePEMFile(args Args, path string) (bool, error) {
+	logrus.Printf("Creating pem file at %q\n", path)
+	if _, err := os.Stat(path); !os.IsNotExist(err) || args.DryRun {
+		return false, nil
+	}
+	// remove filename from path
+	dir := filepath.Dir(path)
+	pemFolderErr := os.MkdirAll(dir, 0700)
+	if pemFolderErr != nil {
+		return false, fmt.Errorf("error creating pem folder: %s", pemFolderErr)
+	}
+	// write pem contents
+	pemWriteErr := os.WriteFile(path, []byte(args.PEMFileContents), 0600)
+	if pemWriteErr != nil {
+		return false, fmt.Errorf("error writing pem file: %s", pemWriteErr)
+	}
+	logrus.Printf("Successfully created pem file at %q\n", path)
+	return true, nil
+}
+
+// copyCert copies the certificate file into dir, under the same name. The
+// error of a missing file is returned as is, see os.IsNotExist.
+func copyCert(path, dir string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating pem folder: %s", err)
 	}
+	if err := os.WriteFile(filepath.Join(dir, filepath.Base(path)), data, 0600); err != nil {
+		return fmt.Errorf("error copying certificate %s: %s", path, err)
+	}
+	return nil
+}
 
-	if args.Command == "cleanup" {
-		logrus.Println("cleanup start")
-		commandsList, err = GetCleanupCommandArgs(args)
+func GetRtCommandsList(args Args) ([][]string, error) {
+	_, handler, err := resolveRtCommand(args)
+	if err != nil {
+		return nil, err
 	}
+	return rtCommandsList(handler, args)
+}
+
+// resolveRtCommand finds and validates the handler of the build tool and
+// command of args.
+func resolveRtCommand(args Args) (rtCommandKey, RtCommandHandler, error) {
+	logrus.Println("Handling rt command handleRtCommand")
+	logrus.Println("Checking GetRtCommandsList args.Command ", args.Command)
 
-	if args.Command == "scan" {
-		logrus.Println("scan start")
-		commandsList, err = GetScanCommandArgs(args)
+	key, handler, err := lookupRtCommand(args.BuildTool, args.Command)
+	if err != nil {
+		return key, nil, err
 	}
+	logrus.Println(key, " start")
 
-	if args.Command == "publish-build-info" {
-		logrus.Println("publish-build-info start")
-		commandsList, err = GetBuildInfoPublishCommandArgs(args)
+	if err := handler.Validate(args); err != nil {
+		if settingsErr, ok := err.(*SettingsError); ok {
+			settingsErr.Command = key.String()
+		}
+		return key, nil, err
 	}
+	return key, handler, nil
+}
 
-	if args.Command == "promote" {
-		logrus.Println("promote start")
-		commandsList, err = GetPromoteCommandArgs(args)
+func rtCommandsList(handler RtCommandHandler, args Args) ([][]string, error) {
+	commandsList, err := handler.Commands(args)
+	if err != nil {
+		return nil, err
 	}
 
-	if args.Command == "add-build-dependencies" {
-		logrus.Println("add-build-dependencies start")
-		commandsList, err = GetAddDependenciesCommandArgs(args)
+	postCommandsList, err := handler.PostCommands(args)
+	if err != nil {
+		return nil, err
 	}
+	return append(commandsList, postCommandsList...), nil
+}
+
+func ExecCommand(ctx context.Context, args Args, cmdArgs []string) error {
 
-	// command "build-discard" Used only by standalone step of build-discard
-	if args.Command == "build-discard" {
-		logrus.Println("build-discard start")
-		commandsList, err = GetBuildDiscardCommandArgs(args)
+	err := runCommand(ctx, args, cmdArgs, os.Stdout)
+	if err != nil {
+		logrus.Println(" Error: ", err)
+		return err
 	}
-	return commandsList, err
+	return nil
 }
 
-func GetShellForOs(osName string) (string, string) {
+// runCommand runs cmdArgs directly, without a shell, writing the command
+// output to stdout. Commands reading a secret from stdin are given the
+// credential matching the auth flags, see setAuthParams. In dry run mode
+// the command is only printed. The process tree is killed when ctx is done
+// or the command timeout expires. Transient failures are retried following
+// the retry policy of args.
+func runCommand(ctx context.Context, args Args, cmdArgs []string, stdout io.Writer) error {
+	if args.DryRun {
+		fmt.Fprintf(os.Stdout, "+ %s\n", newRedactor(args).redact(formatCommand(cmdArgs)))
+		return nil
+	}
 
-	if runtime.GOOS == "windows" {
-		// First check for PowerShell Core (pwsh.exe) which is used in PowerShell Nanoserver
-		if _, err := os.Stat("C:/Program Files/PowerShell/pwsh.exe"); err == nil {
-			return "pwsh", "-Command"
+	policy := newRetryPolicy(args)
+	if !isRetryableCommand(cmdArgs) {
+		policy.maxAttempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			logrus.Printf("Running %s, attempt %d of %d\n", cmdArgs[0], attempt, policy.maxAttempts)
+		}
+		stderr, err := runCommandOnce(ctx, args, cmdArgs, stdout)
+		if err == nil || attempt >= policy.maxAttempts || ctx.Err() != nil || !isTransientFailure(err, stderr) {
+			return err
 		}
 
-		// Fall back to traditional PowerShell
-		return "powershell", "-Command"
+		delay := policy.delay(attempt)
+		logrus.Printf("Attempt %d of %d of %s failed: %s, retrying in %s\n",
+			attempt, policy.maxAttempts, cmdArgs[0], err, delay.Round(time.Millisecond))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
 	}
-
-	return "sh", "-c"
 }
 
-func ExecCommand(args Args, cmdArgs []string) error {
-
-	cmdStr := strings.Join(cmdArgs[:], " ")
-
-	shell, shArg := GetShellForOs(runtime.GOOS)
+// runCommandOnce runs the command a single time, returning the end of its
+// stderr to classify a failure.
+func runCommandOnce(ctx context.Context, args Args, cmdArgs []string, stdout io.Writer) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("not running %s: %s", cmdArgs[0], err)
+	}
 
-	logrus.Println()
-	logrus.Printf("%s %s %s", shell, shArg, cmdStr)
-	logrus.Println()
+	cmdCtx := ctx
+	if args.CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, args.CommandTimeout)
+		defer cancel()
+	}
 
-	cmd := exec.Command(shell, shArg, cmdStr)
-	cmd.Env = os.Environ()
+	cmd := exec.CommandContext(cmdCtx, cmdArgs[0], cmdArgs[1:]...)
+	killProcessTreeOnCancel(cmd)
+	cmd.WaitDelay = processWaitDelay
+	homeEnv, err := jfrogHomeEnv(args)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = childEnv(args)
 	cmd.Env = append(cmd.Env, "JFROG_CLI_OFFER_CONFIG=false")
+	cmd.Env = append(cmd.Env, homeEnv...)
+
+	if readsSecretFromStdin(cmdArgs) {
+		cmd.Stdin = strings.NewReader(getAuthSecret(args))
+	}
+	redactor := newRedactor(args)
+	redactedStdout := newRedactWriter(stdout, redactor)
+	redactedStderr := newRedactWriter(os.Stderr, redactor)
+	stderr := &tailWriter{limit: retryOutputLimit}
+	cmd.Stdout = redactedStdout
+	cmd.Stderr = io.MultiWriter(redactedStderr, stderr)
+	trace(redactor, cmd)
+
+	var captured bytes.Buffer
+	if args.result != nil && capturesOutput(cmdArgs) {
+		cmd.Stdout = io.MultiWriter(redactedStdout, &captured)
+	}
+
+	started := time.Now()
+	err = cmd.Run()
+	redactedStdout.Flush()
+	redactedStderr.Flush()
+
+	args.result.addCommand(redactor.redact(formatCommand(cmdArgs)), time.Since(started), exitCode(err))
+	if err == nil {
+		args.result.recordOutput(cmdArgs, captured.Bytes())
+	}
+	switch {
+	case err == nil:
+		return nil, nil
+	case ctx.Err() == context.DeadlineExceeded:
+		return stderr.buf, fmt.Errorf("step timed out after %s, killed %s", args.Timeout, cmdArgs[0])
+	case ctx.Err() == context.Canceled:
+		return stderr.buf, fmt.Errorf("step cancelled, killed %s", cmdArgs[0])
+	case cmdCtx.Err() == context.DeadlineExceeded:
+		return stderr.buf, fmt.Errorf("command timed out after %s, killed %s", args.CommandTimeout, cmdArgs[0])
+	}
+	return stderr.buf, err
+}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	trace(cmd)
-
-	err := cmd.Run()
-	if err != nil {
-		logrus.Println(" Error: ", err)
-		return err
+// exitCode returns the exit code of a finished command, -1 when it did not
+// exit on its own.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
 	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
 
-	if args.PublishBuildInfo {
-		if err := publishBuildInfo(args); err != nil {
-			logrus.Println("Error publishing build info: ", err)
-			return err
+func readsSecretFromStdin(cmdArgs []string) bool {
+	for _, arg := range cmdArgs {
+		if arg == passwordStdinFlag || arg == accessTokenStdinFlag {
+			return true
 		}
 	}
+	return false
+}
 
-	return nil
+// splitCommandLine splits a user provided argument string, such as maven
+// goals or gradle tasks, into separate arguments. Single and double quotes
+// group words the way a shell would, without any expansion.
+func splitCommandLine(s string) []string {
+	var result []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				result = append(result, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		result = append(result, current.String())
+	}
+	return result
 }
 
 type JsonTagToExeFlagMapStringItem struct {
@@ -217,32 +487,21 @@ func PopulateArgs(tmpCommandsList *[]string, args *Args,
 	for _, jsonTagToExeFlagMapStringItem := range jsonTagToExeFlagMapStringItemList {
 		flagName := jsonTagToExeFlagMapStringItem.FlagName
 		pluginArgJsonTag := jsonTagToExeFlagMapStringItem.PluginArgJsonTag
-		pluginArgValue, err := GetFieldAddress[*Args, string](args, pluginArgJsonTag)
+		pluginArgValue, err := GetFieldFlagValue(args, pluginArgJsonTag)
 
 		if err != nil {
 			if jsonTagToExeFlagMapStringItem.IsMandatory || jsonTagToExeFlagMapStringItem.StopOnError {
-				logrus.Println("GetFieldAddress error: ", err)
+				logrus.Println("GetFieldFlagValue error: ", err)
 				return err
 			}
-			//logrus.Println("GetFieldAddress error: ", err)
-			continue
-		}
-
-		if pluginArgValue == nil {
-			if jsonTagToExeFlagMapStringItem.IsMandatory || jsonTagToExeFlagMapStringItem.StopOnError {
-				logrus.Println("missing mandatory field: ", pluginArgJsonTag)
-				return fmt.Errorf("missing mandatory field %s", pluginArgJsonTag)
-			}
-			logrus.Println("missing mandatory field: ", pluginArgJsonTag)
 			continue
 		}
 
-		if pluginArgValue == nil &&
-			jsonTagToExeFlagMapStringItem.IsMandatory || jsonTagToExeFlagMapStringItem.StopOnError {
+		if jsonTagToExeFlagMapStringItem.IsMandatory && len(pluginArgValue) == 0 {
 			logrus.Println("missing mandatory field: ", pluginArgJsonTag)
-			return fmt.Errorf("missing mandatory field %s", pluginArgJsonTag)
+			return fmt.Errorf("missing mandatory setting %s", pluginArgJsonTag)
 		}
-		AppendStringArg(tmpCommandsList, flagName, pluginArgValue)
+		AppendStringArg(tmpCommandsList, flagName, &pluginArgValue)
 	}
 
 	return nil
@@ -320,11 +579,53 @@ func GetFieldAddress[ST, VT any](args ST, argJsonTag string) (*VT, error) {
 	return nil, fmt.Errorf("field with tag '%s' in struct '%s' cannot be addressed", argJsonTag, t.Name())
 }
 
+// GetFieldFlagValue returns the value of the field tagged argJsonTag formatted
+// as a flag value, or an empty string when the field holds its zero value.
+func GetFieldFlagValue(args *Args, argJsonTag string) (string, error) {
+	v := reflect.ValueOf(args).Elem()
+	t := v.Type()
+
+	fieldIndex, found := getTagMapping(t)[argJsonTag]
+	if !found {
+		return "", fmt.Errorf("field with tag '%s' not found in struct type '%s'", argJsonTag, t.Name())
+	}
+
+	fieldValue := v.Field(fieldIndex)
+	if !isFlagKind(fieldValue.Kind()) {
+		return "", fmt.Errorf("field with tag '%s' in struct '%s' cannot be used as a flag; type is '%s'",
+			argJsonTag, t.Name(), fieldValue.Type().String())
+	}
+	if fieldValue.IsZero() {
+		return "", nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(fieldValue.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fieldValue.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fieldValue.Uint(), 10), nil
+	default:
+		return fieldValue.String(), nil
+	}
+}
+
+func isFlagKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
 func GetConfigAddConfigCommandArgs(srvConfigStr, userName, password, url,
 	accessToken, apiKey string) ([]string, error) {
 
 	if srvConfigStr == "" {
-		srvConfigStr = tmpServerId
+		return []string{""}, fmt.Errorf("missing server config id")
 	}
 
 	authParams, err := setAuthParams([]string{}, Args{Username: userName,
@@ -336,7 +637,7 @@ func GetConfigAddConfigCommandArgs(srvConfigStr, userName, password, url,
 
 	cfgCommand := []string{"config", "add", srvConfigStr, "--url=" + url}
 	cfgCommand = append(cfgCommand, authParams...)
-	cfgCommand = append(cfgCommand, "--interactive=false")
+	cfgCommand = append(cfgCommand, "--interactive=false", "--overwrite=true")
 	return cfgCommand, nil
 }
 
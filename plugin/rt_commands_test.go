@@ -0,0 +1,218 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		input  string
+		output []string
+	}{
+		{"clean install", []string{"clean", "install"}},
+		{"  clean   install  ", []string{"clean", "install"}},
+		{`clean install -Dmsg="hello world"`, []string{"clean", "install", "-Dmsg=hello world"}},
+		{`build -Pname='it''s' -Pempty=""`, []string{"build", "-Pname=its", "-Pempty="}},
+		{`test -Dx=$HOME`, []string{"test", "-Dx=$HOME"}},
+		{"", nil},
+	}
+
+	for _, tc := range tests {
+		result := splitCommandLine(tc.input)
+		if !reflect.DeepEqual(result, tc.output) {
+			t.Errorf("For %q, Expected: %q, Got: %q", tc.input, tc.output, result)
+		}
+	}
+}
+
+func TestGetAuthSecret(t *testing.T) {
+	tests := []struct {
+		args   Args
+		output string
+	}{
+		{Args{Username: "john", Password: "password123", AccessToken: "token123"}, "password123"},
+		{Args{Username: "john", APIKey: "secretkey"}, "secretkey"},
+		{Args{AccessToken: "token123"}, "token123"},
+	}
+
+	for _, tc := range tests {
+		if result := getAuthSecret(tc.args); result != tc.output {
+			t.Errorf("Expected: %s, Got: %s", tc.output, result)
+		}
+	}
+}
+
+func TestReadsSecretFromStdin(t *testing.T) {
+	if !readsSecretFromStdin([]string{"jf", "config", "add", "id", "--password-stdin"}) {
+		t.Errorf("Expected config add with --password-stdin to read from stdin")
+	}
+	if readsSecretFromStdin([]string{"jf", "rt", "u", "--build-name=a b'$c", "src", "target"}) {
+		t.Errorf("Expected upload not to read from stdin")
+	}
+}
+
+func TestRunCommandTimeoutKillsProcessTree(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	args := Args{CommandTimeout: 200 * time.Millisecond}
+
+	var out bytes.Buffer
+	start := time.Now()
+	// the background sleep keeps the output open unless the whole tree is killed
+	err := runCommand(context.Background(), args, []string{"sh", "-c", "sleep 30 & sleep 30"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "command timed out after 200ms") {
+		t.Errorf("Expected command timeout error, Got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the process tree to be killed, took %s", elapsed)
+	}
+}
+
+func TestRunCommandCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := runCommand(ctx, Args{}, []string{"jf", "rt", "ping"}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("Expected cancelled error, Got: %v", err)
+	}
+}
+
+func TestDryRunMavenPublish(t *testing.T) {
+	args := Args{
+		DryRun:      true,
+		Username:    "ab",
+		Password:    "cd3f",
+		BuildTool:   RtMvnBuildTool,
+		Command:     "publish",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		URL:         RtUrlTestStr,
+		DeployerId:  RtDeployerId,
+		MaxBuilds:   "5",
+	}
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"+ jf config add deploy_gen_maven_01 --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin " +
+			"--interactive=false --overwrite=true",
+		"+ jf mvn-config",
+		"+ jf mvn deploy --build-name=t2 --build-number=v1.0",
+		"+ jf rt build-publish t2 v1.0 --server-id=deploy_gen_maven_01",
+		"+ jf config add tmpServerIdbdi --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin " +
+			"--interactive=false --overwrite=true",
+		"+ jf rt build-discard --server-id=tmpServerIdbdi --max-builds=5 t2",
+	}
+	gotCmds := strings.Split(strings.TrimSpace(out), "\n")
+	if len(gotCmds) != len(wantCmds) {
+		t.Fatalf("Expected %d commands, but got %d: %s", len(wantCmds), len(gotCmds), out)
+	}
+	for i := range wantCmds {
+		if gotCmds[i] != wantCmds[i] {
+			t.Errorf("Command mismatch at index %d:\nExpected: %q\nGot:      %q", i, wantCmds[i], gotCmds[i])
+		}
+	}
+}
+
+func TestDryRunUpload(t *testing.T) {
+	args := Args{
+		DryRun:      true,
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		Source:      "/harness/cache.txt",
+		Target:      "repo/dir/",
+		BuildName:   "my build",
+		BuildNumber: RtBuildNumber,
+	}
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "+ jf rt u --url=https://artifactory.test.io/artifactory/ --server-id=tmpServerId --flat=false " +
+		"--build-number=v1.0 \"--build-name=my build\" /harness/cache.txt repo/dir/"
+	if !strings.Contains(out, want) {
+		t.Errorf("Expected: %s, Got: %s", want, out)
+	}
+}
+
+func TestDryRunUploadAutoProps(t *testing.T) {
+	args := Args{
+		DryRun:      true,
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		Source:      "cache.txt",
+		Target:      "repo/dir/",
+		TargetProps: "team=core",
+		AutoProps:   true,
+	}
+	args.Commit.Rev = "6d2f1a8"
+	args.Build.Event = "push"
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "--target-props=team=core;vcs.revision=6d2f1a8;ci.event=push cache.txt repo/dir/"
+	if !strings.Contains(out, want) {
+		t.Errorf("Expected: %s, Got: %s", want, out)
+	}
+}
+
+// installFakeJf puts a jf shell script running script first on the PATH,
+// skipping the test on Windows.
+func installFakeJf(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake jf binary is a shell script")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jf"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// serverIdSuffix matches the random suffix of the step's server config ids,
+// see stepServerId.
+var serverIdSuffix = regexp.MustCompile(`(tmpServerId\S*?)-[0-9a-f]{8}\b`)
+
+// captureStdout runs fn and returns everything it wrote to os.Stdout, the
+// server config ids without their random suffix so outputs can be compared.
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+
+	fnErr := fn()
+	w.Close()
+	return serverIdSuffix.ReplaceAllString(<-done, "$1"), fnErr
+}
@@ -1,14 +1,48 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"time"
+	"strings"
 )
 
+func init() {
+	RegisterRtCommand("", "download", rtCommand{
+		rules: settingRules{
+			auth:         true,
+			oneOf:        [][]string{{"PLUGIN_SPEC", "PLUGIN_SPEC_PATH", "PLUGIN_SOURCE"}},
+			exclusive:    [][]string{{"PLUGIN_SPEC", "PLUGIN_SPEC_PATH"}},
+			flagMaps:     [][]JsonTagToExeFlagMapStringItem{DownloadCmdJsonTagToExeFlagMapStringItemList},
+			nativeClient: true,
+		},
+		validate: func(args Args) []string {
+			if args.Client == ClientNative {
+				return checkNativeDownload(args)
+			}
+			switch {
+			case args.Spec != "" && args.SpecPath == "":
+				return checkSpec("PLUGIN_SPEC", args.Spec, args.SpecVars, specDownload, args.Pipeline)
+			case args.SpecPath != "" && args.Spec == "":
+				return checkSpec("PLUGIN_SPEC_PATH", args.SpecPath, args.SpecVars, specDownload, args.Pipeline)
+			}
+			return nil
+		},
+		commands:  GetDownloadCommandArgs,
+		runNative: runNativeDownload,
+	})
+	RegisterRtCommand("", "cleanup", rtCommand{
+		rules: settingRules{
+			required: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		},
+		commands:     GetCleanupCommandArgs,
+		postCommands: postCommandsHandling(postActionBuildClean),
+	})
+}
+
 var DownloadCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
 	{"--build-name=", "PLUGIN_BUILD_NAME", false, false},
 	{"--build-number=", "PLUGIN_BUILD_NUMBER", false, false},
+	{"--detailed-summary=", "PLUGIN_DETAILED_SUMMARY", false, false},
 	{"--module=", "PLUGIN_MODULE", false, false},
 	{"--project=", "PLUGIN_PROJECT", false, false},
 	{"--url=", "PLUGIN_URL", false, false},
@@ -19,61 +53,81 @@ var DownloadCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringIt
 func GetDownloadCommandArgs(args Args) ([][]string, error) {
 
 	var cmdList [][]string
-	downloadCommandArgs := []string{"rt", "download"}
+	serverId := stepServerId(args, "")
+	downloadCommandArgs := []string{"rt", "download", "--server-id=" + serverId}
 
-	authParams, err := setAuthParams([]string{}, Args{Username: args.Username,
-		Password: args.Password, AccessToken: args.AccessToken, APIKey: args.APIKey})
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
 
-	if args.Spec != "" {
-		fileName := getTimestampedFileName()
-		err = writeToFile(fileName, args.Spec)
+	if args.Spec != "" || args.SpecPath != "" {
+		spec := args.Spec
+		if spec == "" {
+			spec = args.SpecPath
+		}
+		specPath, err := materializeSpec(args.workspace, spec, args.Pipeline)
 		if err != nil {
 			return cmdList, err
 		}
 		args.Spec = ""
-		args.SpecPath = fileName
+		args.SpecPath = specPath
 	}
 
-	downloadCommandArgs = append(downloadCommandArgs, authParams...)
-	downloadCommandArgs = append(downloadCommandArgs, args.Target, args.Source)
-	downloadCommandArgs = append(downloadCommandArgs)
+	for _, arg := range []string{args.Target, args.Source} {
+		if arg != "" {
+			downloadCommandArgs = append(downloadCommandArgs, arg)
+		}
+	}
 
 	err = PopulateArgs(&downloadCommandArgs, &args, DownloadCmdJsonTagToExeFlagMapStringItemList)
 	if err != nil {
 		return cmdList, err
 	}
 
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, downloadCommandArgs)
 	return cmdList, nil
 }
 
-func GetCleanupCommandArgs(args Args) ([][]string, error) {
-	var cmdList [][]string
-	cleanupCommandArgs := []string{"rt", "build-clean", args.BuildName, args.BuildNumber}
-	cmdList = append(cmdList, cleanupCommandArgs)
-	return cmdList, nil
+// checkNativeDownload returns the settings of args the native client cannot
+// download with.
+func checkNativeDownload(args Args) []string {
+	set := setSettings(&args, []string{"PLUGIN_SPEC", "PLUGIN_SPEC_PATH", "PLUGIN_BUILD_NAME", "PLUGIN_MODULE"})
+	if len(set) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("the native client does not support %s", strings.Join(set, ", "))}
 }
 
-func writeToFile(filePath, content string) error {
-	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+// runNativeDownload downloads through the REST API, taking the same
+// positional arguments as the jf download of GetDownloadCommandArgs.
+func runNativeDownload(ctx context.Context, args Args) error {
+	client, err := newRestClient(args)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %v", err)
+		return err
 	}
-	defer file.Close()
 
-	_, err = file.WriteString(content)
-	if err != nil {
-		return fmt.Errorf("failed to write to file: %v", err)
+	var positional []string
+	for _, arg := range []string{args.Target, args.Source} {
+		if arg != "" {
+			positional = append(positional, arg)
+		}
+	}
+	pattern, target := positional[0], ""
+	if len(positional) > 1 {
+		target = positional[1]
 	}
 
-	return nil
+	artifacts, err := client.Download(ctx, pattern, target)
+	args.result.addArtifacts(artifacts)
+	return err
 }
 
-func getTimestampedFileName() string {
-	timestamp := time.Now().Format("20060102_150405.000")
-	fileName := fmt.Sprintf("%s_spec.json", timestamp)
-	return fileName
+func GetCleanupCommandArgs(args Args) ([][]string, error) {
+	var cmdList [][]string
+	cleanupCommandArgs := []string{"rt", "build-clean", args.BuildName, args.BuildNumber}
+	cmdList = append(cmdList, cleanupCommandArgs)
+	return cmdList, nil
 }
@@ -28,7 +28,9 @@ func TestGetDownloadCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"rt download --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD   " + "--build-name=t2 --build-number=v1.0 " +
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin " +
+			"--interactive=false --overwrite=true",
+		"rt download --server-id=tmpServerId " + "--build-name=t2 --build-number=v1.0 " +
 			"--module=backend_module --project=backend_project --url=https://artifactory.test.io/artifactory/ --spec=spec.json",
 	}
 
@@ -57,7 +59,9 @@ func TestGetDownloadCommandUserAccessToken(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"rt download --access-token $PLUGIN_ACCESS_TOKEN   --build-name=t2 --build-number=v1.0 --module=backend_module" +
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --access-token-stdin " +
+			"--interactive=false --overwrite=true",
+		"rt download --server-id=tmpServerId --build-name=t2 --build-number=v1.0 --module=backend_module" +
 			" --project=backend_project --url=https://artifactory.test.io/artifactory/ --spec=spec.json",
 	}
 
@@ -0,0 +1,175 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	postActionBuildPublish = "build-publish"
+	postActionBuildDiscard = "build-discard"
+	postActionBuildClean   = "build-clean"
+)
+
+// postAction is a lifecycle hook of the build-info, run once after the main
+// commands of a step.
+type postAction struct {
+	name     string
+	enabled  func(args Args) bool
+	commands func(args Args) ([][]string, error)
+}
+
+// postActions lists the lifecycle hooks in the order they run.
+var postActions = []postAction{
+	{
+		name:     postActionBuildPublish,
+		enabled:  func(args Args) bool { return args.PublishBuildInfo },
+		commands: GetPublishBuildInfoPostCommandArgs,
+	},
+	{
+		name:     postActionBuildDiscard,
+		enabled:  func(args Args) bool { return IsBuildDiscardArgs(args) && publishesBuildInfo(args) },
+		commands: GetBuildDiscardCommandArgs,
+	},
+	{
+		name:     postActionBuildClean,
+		enabled:  func(args Args) bool { return parseBoolOrDefault(false, args.BuildClean) },
+		commands: GetCleanupCommandArgs,
+	},
+}
+
+// buildInfoPublishCommands lists the commands always publishing build-info,
+// after which the old builds are discarded. The build-discard command
+// discards on its own.
+var buildInfoPublishCommands = map[string]bool{
+	Publish:              true,
+	"publish-build-info": true,
+}
+
+// publishesBuildInfo reports whether the command of args publishes
+// build-info, the other commands leave the discard settings unused. Uploads
+// publish only with PLUGIN_PUBLISH_BUILD_INFO.
+func publishesBuildInfo(args Args) bool {
+	command := args.Command
+	if command == "" && args.BuildTool == "" {
+		command = uploadCommandName
+	}
+	if command == uploadCommandName {
+		return args.PublishBuildInfo
+	}
+	return buildInfoPublishCommands[command]
+}
+
+// checkPostActions returns the problems preventing the post actions from
+// running, build-info publish is checked by checkPublishBuildInfo.
+func checkPostActions(args Args) []string {
+	if args.BuildClean == "" {
+		return nil
+	}
+	if _, err := strconv.ParseBool(args.BuildClean); err != nil {
+		return []string{fmt.Sprintf("PLUGIN_BUILD_CLEAN must be true or false, got %q", args.BuildClean)}
+	}
+	if parseBoolOrDefault(false, args.BuildClean) && (args.BuildName == "" || args.BuildNumber == "") {
+		return []string{"PLUGIN_BUILD_CLEAN requires PLUGIN_BUILD_NAME and PLUGIN_BUILD_NUMBER"}
+	}
+	return nil
+}
+
+// lifecyclePostCommands returns the commands of the post actions enabled in
+// args, except the ones the command handles itself.
+func lifecyclePostCommands(args Args, handled ...string) ([][]string, error) {
+	skip := map[string]bool{}
+	for _, name := range handled {
+		skip[name] = true
+	}
+
+	var cmdList [][]string
+	for _, action := range postActions {
+		if skip[action.name] {
+			continue
+		}
+		commands, err := action.postCommands(args)
+		if err != nil {
+			return nil, err
+		}
+		cmdList = append(cmdList, commands...)
+	}
+	return cmdList, nil
+}
+
+// postCommands returns the commands of the action, none when it is not
+// enabled in args.
+func (a postAction) postCommands(args Args) ([][]string, error) {
+	if !a.enabled(args) {
+		return nil, nil
+	}
+	commands, err := a.commands(args)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing %s: %s", a.name, err)
+	}
+	return commands, nil
+}
+
+// postCommandsHandling returns the post commands of a command handling the
+// given post actions itself.
+func postCommandsHandling(handled ...string) func(args Args) ([][]string, error) {
+	return func(args Args) ([][]string, error) {
+		return lifecyclePostCommands(args, handled...)
+	}
+}
+
+// GetPublishBuildInfoPostCommandArgs returns the commands publishing the
+// build-info of PLUGIN_PUBLISH_BUILD_INFO, through a server config of its own.
+func GetPublishBuildInfoPostCommandArgs(args Args) ([][]string, error) {
+	if args.BuildName == "" || args.BuildNumber == "" {
+		return nil, fmt.Errorf("both build name and build number need to be set when publishing build info")
+	}
+
+	sanitizedURL, err := sanitizeURL(args.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.AccessToken == "" && (args.Username == "" || args.Password == "") {
+		return nil, fmt.Errorf("either access token or username/password need to be set for publishing build info")
+	}
+
+	bpiServerId := stepServerId(args, "bpi")
+	configCmdArgs, err := GetConfigAddConfigCommandArgs(bpiServerId, args.Username, args.Password,
+		sanitizedURL, args.AccessToken, "")
+	if err != nil {
+		return nil, err
+	}
+	publishCmdArgs := []string{"rt", BuildPublish, args.BuildName, args.BuildNumber, "--server-id=" + bpiServerId}
+	return [][]string{configCmdArgs, publishCmdArgs}, nil
+}
+
+// runPostCommands runs the post commands once the main commands are done,
+// mainErr is their outcome. Post commands run after a failure only with
+// PLUGIN_POST_ACTIONS_ON_FAILURE, for example to publish partial build-info,
+// and the step still fails with mainErr.
+func runPostCommands(ctx context.Context, args Args, cmdList [][]string, mainErr error) error {
+	if mainErr != nil && !args.PostActionsOnFailure {
+		return mainErr
+	}
+	if mainErr != nil && len(cmdList) > 0 {
+		logrus.Println("Running the post actions after the failure: ", mainErr)
+	}
+
+	var err error
+	for _, cmd := range cmdList {
+		execArgs := append([]string{getJfrogBin()}, cmd...)
+		if err = runCommand(ctx, args, execArgs, os.Stdout); err != nil {
+			logrus.Println("Error Unable to run post action err = ", err)
+			break
+		}
+	}
+	if mainErr != nil {
+		return mainErr
+	}
+	return err
+}
@@ -0,0 +1,207 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLifecyclePostCommands(t *testing.T) {
+	args := Args{
+		URL:              RtUrlTestStr,
+		AccessToken:      RtAccessToken,
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+		MaxBuilds:        "5",
+		BuildClean:       "true",
+	}
+
+	cmdList, err := lifecyclePostCommands(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var gotCmds []string
+	for _, cmd := range cmdList {
+		gotCmds = append(gotCmds, strings.Join(cmd, " "))
+	}
+	wantCmds := []string{
+		"config add tmpServerIdbpi --url=https://artifactory.test.io/artifactory/ --access-token-stdin " +
+			"--interactive=false --overwrite=true",
+		"rt build-publish t2 v1.0 --server-id=tmpServerIdbpi",
+		"config add tmpServerIdbdi --url=https://artifactory.test.io/artifactory/ --access-token-stdin " +
+			"--interactive=false --overwrite=true",
+		"rt build-discard --server-id=tmpServerIdbdi --max-builds=5 t2",
+		"rt build-clean t2 v1.0",
+	}
+	if strings.Join(gotCmds, "\n") != strings.Join(wantCmds, "\n") {
+		t.Errorf("Expected:\n%s\nGot:\n%s", strings.Join(wantCmds, "\n"), strings.Join(gotCmds, "\n"))
+	}
+
+	cmdList, err = lifecyclePostCommands(args, postActionBuildPublish, postActionBuildDiscard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cmdList) != 1 || strings.Join(cmdList[0], " ") != "rt build-clean t2 v1.0" {
+		t.Errorf("Expected only build-clean, Got: %v", cmdList)
+	}
+}
+
+func TestBuildDiscardOnlyAfterPublish(t *testing.T) {
+	args := Args{
+		URL:         RtUrlTestStr,
+		AccessToken: RtAccessToken,
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		MaxBuilds:   "5",
+	}
+
+	for command, want := range map[string]int{
+		"":                   0,
+		"upload":             0,
+		"publish-build-info": 2,
+		"download":           0,
+		"promote":            0,
+		"scan":               0,
+	} {
+		args.Command = command
+		cmdList, err := lifecyclePostCommands(args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(cmdList) != want {
+			t.Errorf("For command %q, Expected %d commands, Got: %v", command, want, cmdList)
+		}
+	}
+
+	// with the build-info published, uploads discard the old builds too
+	args.Command, args.PublishBuildInfo = "upload", true
+	cmdList, err := lifecyclePostCommands(args, postActionBuildPublish)
+	if err != nil || len(cmdList) != 2 || cmdList[1][1] != "build-discard" {
+		t.Errorf("Expected build-discard after an upload publishing build-info, Got: %v, %v", cmdList, err)
+	}
+
+	args.PublishBuildInfo = false
+	args.BuildTool, args.Command = MvnCmd, defaultBuildToolCommand
+	if cmdList, err := lifecyclePostCommands(args); err != nil || len(cmdList) != 0 {
+		t.Errorf("Expected no build-discard after a Maven build, Got: %v, %v", cmdList, err)
+	}
+}
+
+func TestDryRunPublishBuildInfoOnce(t *testing.T) {
+	args := Args{
+		DryRun:           true,
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Command:          "promote",
+		Target:           "release-repo",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+	}
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count := strings.Count(out, "build-publish"); count != 1 {
+		t.Errorf("Expected build-info to be published once, Got %d times: %s", count, out)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(out), "+ jf rt build-publish t2 v1.0 --server-id=tmpServerIdbpi") {
+		t.Errorf("Expected build-info to be published last, Got: %s", out)
+	}
+}
+
+func TestPostActionsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	fakeJf := `#!/bin/sh
+echo "$1 $2" >> ` + calls + `
+[ "$1 $2" = "rt build-promote" ] && exit 3
+exit 0
+`
+	installFakeJf(t, fakeJf)
+
+	args := Args{
+		AccessToken:      RtAccessToken,
+		URL:              RtUrlTestStr,
+		Command:          "promote",
+		Target:           "release-repo",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+	}
+	for _, tc := range []struct {
+		onFailure bool
+		want      string
+	}{
+		{false, "config add\nrt build-promote\n"},
+		{true, "config add\nrt build-promote\nconfig add\nrt build-publish\n"},
+	} {
+		os.Remove(calls)
+		args.PostActionsOnFailure = tc.onFailure
+
+		err := Exec(context.Background(), args)
+		if err == nil || err.Error() != "exit status 3" {
+			t.Errorf("Expected the promote error, Got: %v", err)
+		}
+		got, _ := os.ReadFile(calls)
+		if string(got) != tc.want {
+			t.Errorf("Expected calls:\n%s\nGot:\n%s", tc.want, got)
+		}
+	}
+}
+
+func TestUploadPostActionsOnFailure(t *testing.T) {
+	t.Setenv("EU_TOKEN", "eu-token")
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	fakeJf := `#!/bin/sh
+echo "$1 $2" >> ` + calls + `
+[ "$1 $2" = "rt u" ] && exit 3
+exit 0
+`
+	installFakeJf(t, fakeJf)
+
+	args := Args{
+		AccessToken:          RtAccessToken,
+		URL:                  RtUrlTestStr,
+		Source:               "a.txt",
+		Target:               "repo/",
+		BuildName:            RtBuildName,
+		BuildNumber:          RtBuildNumber,
+		PublishBuildInfo:     true,
+		BuildClean:           "true",
+		PostActionsOnFailure: true,
+		Servers:              `[{"name": "eu", "url": "https://eu.test.io/artifactory/", "access_token_env": "EU_TOKEN"}]`,
+	}
+	err := Exec(context.Background(), args)
+	if err == nil {
+		t.Fatal("Expected the upload error")
+	}
+	// the partial build-info is published to both servers before it is cleaned
+	want := "config add\nrt u\nconfig add\nrt u\n" +
+		"config add\nrt build-publish\nconfig add\nrt build-publish\nrt build-clean\n"
+	if got, _ := os.ReadFile(calls); string(got) != want {
+		t.Errorf("Expected calls:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestCheckPostActions(t *testing.T) {
+	tests := []struct {
+		args Args
+		want string
+	}{
+		{Args{BuildClean: "true", BuildName: RtBuildName, BuildNumber: RtBuildNumber}, ""},
+		{Args{BuildClean: "false"}, ""},
+		{Args{BuildClean: "yes"}, `PLUGIN_BUILD_CLEAN must be true or false, got "yes"`},
+		{Args{BuildClean: "true"}, "PLUGIN_BUILD_CLEAN requires PLUGIN_BUILD_NAME and PLUGIN_BUILD_NUMBER"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(checkPostActions(tt.args), "; "); got != tt.want {
+			t.Errorf("Expected: %s, Got: %s", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultBuildToolCommand = "build"
+
+// RtCommandHandler resolves the jf commands run for a plugin command.
+type RtCommandHandler interface {
+	// Validate checks the settings before any command is built or run.
+	Validate(args Args) error
+
+	// Commands returns the jf commands of the main command.
+	Commands(args Args) ([][]string, error)
+
+	// PostCommands returns the jf commands of the post actions, run once
+	// after the main commands, see runPostCommands.
+	PostCommands(args Args) ([][]string, error)
+}
+
+// rtCommand is a RtCommandHandler built from declared setting rules and
+// functions, nil functions are treated as having nothing to do, except
+// postCommands defaulting to the lifecycle post actions. Commands
+// implemented in Go set run, which replaces the jf commands, and commands
+// with a native backend set runNative, replacing them with
+// PLUGIN_CLIENT=native.
+type rtCommand struct {
+	rules        settingRules
+	validate     func(args Args) []string
+	commands     func(args Args) ([][]string, error)
+	postCommands func(args Args) ([][]string, error)
+	run          func(ctx context.Context, args Args) error
+	runNative    func(ctx context.Context, args Args) error
+}
+
+// runner returns the function running the command for args, nil when the
+// jf commands are run.
+func (c rtCommand) runner(args Args) func(ctx context.Context, args Args) error {
+	if args.Client == ClientNative && c.runNative != nil {
+		return c.runNative
+	}
+	return c.run
+}
+
+// Validate checks the declared rules and the validate function, returning
+// a single *SettingsError listing every problem found.
+func (c rtCommand) Validate(args Args) error {
+	problems := c.rules.check(args)
+	problems = append(problems, checkPublishBuildInfo(args)...)
+	problems = append(problems, checkPostActions(args)...)
+	problems = append(problems, checkRetry(args)...)
+	if c.validate != nil {
+		problems = append(problems, c.validate(args)...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &SettingsError{Problems: problems}
+}
+
+func (c rtCommand) Commands(args Args) ([][]string, error) {
+	if c.commands == nil {
+		return nil, nil
+	}
+	return c.commands(args)
+}
+
+func (c rtCommand) PostCommands(args Args) ([][]string, error) {
+	if c.postCommands == nil {
+		return lifecyclePostCommands(args)
+	}
+	return c.postCommands(args)
+}
+
+type rtCommandKey struct {
+	buildTool string
+	command   string
+}
+
+func (k rtCommandKey) String() string {
+	if k.buildTool == "" {
+		return k.command
+	}
+	return k.buildTool + " " + k.command
+}
+
+var rtCommandRegistry = map[rtCommandKey]RtCommandHandler{}
+
+// RegisterRtCommand registers the handler of a command. Commands registered
+// with an empty build tool are available for every build tool.
+func RegisterRtCommand(buildTool, command string, handler RtCommandHandler) {
+	key := rtCommandKey{buildTool: buildTool, command: command}
+	if _, exists := rtCommandRegistry[key]; exists {
+		panic(fmt.Sprintf("rt command %q is already registered", key))
+	}
+	rtCommandRegistry[key] = handler
+}
+
+// SupportedRtCommands returns the registered build tool and command combinations.
+func SupportedRtCommands() []string {
+	supported := make([]string, 0, len(rtCommandRegistry))
+	for key := range rtCommandRegistry {
+		supported = append(supported, key.String())
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// lookupRtCommand finds the handler for the build tool and command, falling
+// back to the generic command when the build tool has no specific one.
+func lookupRtCommand(buildTool, command string) (rtCommandKey, RtCommandHandler, error) {
+	if buildTool != "" && command == "" {
+		command = defaultBuildToolCommand
+	}
+
+	key := rtCommandKey{buildTool: buildTool, command: command}
+	if handler, ok := rtCommandRegistry[key]; ok {
+		return key, handler, nil
+	}
+	genericKey := rtCommandKey{command: command}
+	if handler, ok := rtCommandRegistry[genericKey]; ok {
+		return genericKey, handler, nil
+	}
+
+	var unsupported string
+	if buildTool == "" {
+		unsupported = fmt.Sprintf("command %q", command)
+	} else {
+		unsupported = fmt.Sprintf("build tool %q with command %q", buildTool, command)
+	}
+	return key, nil, fmt.Errorf("unsupported %s, supported commands are: %s",
+		unsupported, strings.Join(SupportedRtCommands(), ", "))
+}
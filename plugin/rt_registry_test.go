@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupRtCommand(t *testing.T) {
+	tests := []struct {
+		buildTool string
+		command   string
+		key       string
+		err       string
+	}{
+		{buildTool: "mvn", command: "", key: "mvn build"},
+		{buildTool: "mvn", command: "publish", key: "mvn publish"},
+		{buildTool: "gradle", command: "build", key: "gradle build"},
+		{buildTool: "", command: "promote", key: "promote"},
+		{buildTool: "mvn", command: "download", key: "download"},
+		{buildTool: "", command: "promte", err: `unsupported command "promte", supported commands are: ` +
+			"add-build-dependencies, build-discard, cleanup, download, gradle build, gradle publish, " +
+			"mvn build, mvn publish, promote, publish-build-info, scan, upload"},
+		{buildTool: "npm", command: "", err: `unsupported build tool "npm" with command "build"`},
+	}
+
+	for _, tc := range tests {
+		key, handler, err := lookupRtCommand(tc.buildTool, tc.command)
+		if tc.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Errorf("Expected error: %s, Got: %v", tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if key.String() != tc.key || handler == nil {
+			t.Errorf("Expected: %s, Got: %s", tc.key, key)
+		}
+	}
+}
+
+func TestGetRtCommandsListUnknownCommand(t *testing.T) {
+	_, err := GetRtCommandsList(Args{Command: "promte", URL: RtUrlTestStr, AccessToken: RtAccessToken})
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown command")
+	}
+}
+
+func TestRegisterRtCommand(t *testing.T) {
+	RegisterRtCommand("", "test-command", rtCommand{
+		commands: func(args Args) ([][]string, error) {
+			return [][]string{{"rt", "ping"}}, nil
+		},
+		postCommands: func(args Args) ([][]string, error) {
+			return [][]string{{"rt", "build-clean", args.BuildName, args.BuildNumber}}, nil
+		},
+	})
+	defer delete(rtCommandRegistry, rtCommandKey{command: "test-command"})
+
+	cmdList, err := GetRtCommandsList(Args{Command: "test-command", BuildName: RtBuildName, BuildNumber: RtBuildNumber})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cmdList) != 2 || strings.Join(cmdList[1], " ") != "rt build-clean t2 v1.0" {
+		t.Errorf("Unexpected commands: %q", cmdList)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected registering a command twice to panic")
+		}
+	}()
+	RegisterRtCommand("", "test-command", rtCommand{})
+}
@@ -5,6 +5,46 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	RegisterRtCommand("", "scan", rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		},
+		commands: GetScanCommandArgs,
+	})
+	RegisterRtCommand("", "publish-build-info", rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+		},
+		commands:     GetBuildInfoPublishCommandArgs,
+		postCommands: postCommandsHandling(postActionBuildPublish),
+	})
+	RegisterRtCommand("", "promote", rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER", "PLUGIN_TARGET"},
+		},
+		commands: GetPromoteCommandArgs,
+	})
+	RegisterRtCommand("", "add-build-dependencies", rtCommand{
+		rules: settingRules{
+			auth:     true,
+			required: []string{"PLUGIN_BUILD_NAME", "PLUGIN_BUILD_NUMBER"},
+			oneOf:    [][]string{{"PLUGIN_SPEC_PATH", "PLUGIN_DEPENDENCY"}},
+			flagMaps: [][]JsonTagToExeFlagMapStringItem{AddDependenciesCmdJsonToExeFlagMapItemList},
+		},
+		validate: func(args Args) []string {
+			if args.SpecPath != "" {
+				return checkSpec("PLUGIN_SPEC_PATH", args.SpecPath, args.SpecVars, specDownload, args.Pipeline)
+			}
+			return nil
+		},
+		commands: GetAddDependenciesCommandArgs,
+	})
+}
+
 func GetScanCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
 
@@ -12,8 +52,9 @@ func GetScanCommandArgs(args Args) ([][]string, error) {
 		return cmdList, errors.New("Valid BuildName and BuildNumber are required")
 	}
 
-	authParams, err := setAuthParams([]string{}, Args{Username: args.Username,
-		Password: args.Password, AccessToken: args.AccessToken, APIKey: args.APIKey})
+	serverId := stepServerId(args, "")
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
@@ -21,7 +62,8 @@ func GetScanCommandArgs(args Args) ([][]string, error) {
 	scanCommandArgs := []string{
 		"build-scan", args.BuildName, args.BuildNumber}
 	scanCommandArgs = append(scanCommandArgs, "--url="+args.URL)
-	scanCommandArgs = append(scanCommandArgs, authParams...)
+	scanCommandArgs = append(scanCommandArgs, "--server-id="+serverId)
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, scanCommandArgs)
 
 	return cmdList, nil
@@ -30,14 +72,15 @@ func GetScanCommandArgs(args Args) ([][]string, error) {
 func GetBuildInfoPublishCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
 
-	tmpServerId := tmpServerId
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
+	serverId := stepServerId(args, "")
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		logrus.Println("GetConfigAddConfigCommandArgs error: ", err)
 		return cmdList, err
 	}
-	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber}
+	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
 	err = PopulateArgs(&buildInfoCommandArgs, &args, nil)
 	if err != nil {
 		return cmdList, err
@@ -50,17 +93,21 @@ func GetBuildInfoPublishCommandArgs(args Args) ([][]string, error) {
 func GetPromoteCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
 
+	serverId := stepServerId(args, "")
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
+		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
+	if err != nil {
+		return cmdList, err
+	}
+
 	promoteCommandArgs := []string{"rt", "build-promote"}
 	if args.Copy != "" {
 		promoteCommandArgs = append(promoteCommandArgs, "--copy="+args.Copy)
 	}
 	promoteCommandArgs = append(promoteCommandArgs, "--url="+args.URL)
+	promoteCommandArgs = append(promoteCommandArgs, "--server-id="+serverId)
 	promoteCommandArgs = append(promoteCommandArgs, args.BuildName, args.BuildNumber, args.Target)
-	authParams, err := setAuthParams([]string{}, Args{Username: args.Username, Password: args.Password, AccessToken: args.AccessToken, APIKey: args.APIKey})
-	if err != nil {
-		return cmdList, err
-	}
-	promoteCommandArgs = append(promoteCommandArgs, authParams...)
+	cmdList = append(cmdList, jfrogConfigAddConfigCommandArgs)
 	cmdList = append(cmdList, promoteCommandArgs)
 	return cmdList, nil
 }
@@ -78,25 +125,40 @@ var AddDependenciesCmdJsonToExeFlagMapItemList = []JsonTagToExeFlagMapStringItem
 func GetAddDependenciesCommandArgs(args Args) ([][]string, error) {
 	var cmdList [][]string
 
-	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(tmpServerId,
+	// PLUGIN_SERVER_ID names the server config holding the credentials
+	serverId := args.ServerId
+	if serverId == "" {
+		serverId = stepServerId(args, "")
+	}
+
+	jfrogConfigAddConfigCommandArgs, err := GetConfigAddConfigCommandArgs(serverId,
 		args.Username, args.Password, args.URL, args.AccessToken, args.APIKey)
 	if err != nil {
 		return cmdList, err
 	}
 
+	if args.SpecPath != "" {
+		if args.SpecPath, err = materializeSpec(args.workspace, args.SpecPath, args.Pipeline); err != nil {
+			return cmdList, err
+		}
+	}
+
 	addDependenciesCommandArgs := []string{"rt", "build-add-dependencies"}
 	err = PopulateArgs(&addDependenciesCommandArgs, &args, AddDependenciesCmdJsonToExeFlagMapItemList)
 	if err != nil {
 		return cmdList, err
 	}
-	addDependenciesCommandArgs = append(addDependenciesCommandArgs, "--server-id="+tmpServerId)
+	if args.ServerId == "" {
+		addDependenciesCommandArgs = append(addDependenciesCommandArgs, "--server-id="+serverId)
+	}
 
 	addDependenciesCommandArgs = append(addDependenciesCommandArgs, args.BuildName, args.BuildNumber)
 	if args.DependencyPattern != "" {
 		addDependenciesCommandArgs = append(addDependenciesCommandArgs, args.DependencyPattern)
 	}
 
-	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber}
+	buildInfoCommandArgs := []string{"rt", "build-publish", args.BuildName, args.BuildNumber,
+		"--server-id=" + serverId}
 	err = PopulateArgs(&buildInfoCommandArgs, &args, nil)
 	if err != nil {
 		return cmdList, err
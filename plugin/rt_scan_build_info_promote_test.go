@@ -20,7 +20,9 @@ func TestGetScanCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"build-scan t2 v1.0",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin " +
+			"--interactive=false --overwrite=true",
+		"build-scan t2 v1.0 --url=https://artifactory.test.io/artifactory/ --server-id=tmpServerId",
 	}
 
 	for i, cmd := range cmdList {
@@ -49,9 +51,9 @@ func TestGetBuildInfoPublishCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME " +
-			"--password $PLUGIN_PASSWORD --interactive=false",
-		"rt build-publish t2 v1.0",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab " +
+			"--password-stdin --interactive=false --overwrite=true",
+		"rt build-publish t2 v1.0 --server-id=tmpServerId",
 	}
 
 	for i, cmd := range cmdList {
@@ -79,8 +81,10 @@ func TestPromoteBuildCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"rt build-promote --copy=true --url=https://artifactory.test.io/artifactory/ t2 v1.0 promoted-repo " +
-			"--user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin " +
+			"--interactive=false --overwrite=true",
+		"rt build-promote --copy=true --url=https://artifactory.test.io/artifactory/ --server-id=tmpServerId " +
+			"t2 v1.0 promoted-repo",
 	}
 
 	for i, cmd := range cmdList {
@@ -109,9 +113,9 @@ func TestAddDependenciesCommandUserPassword(t *testing.T) {
 	}
 
 	wantCmds := []string{
-		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user $PLUGIN_USERNAME --password $PLUGIN_PASSWORD --interactive=false",
+		"config add tmpServerId --url=https://artifactory.test.io/artifactory/ --user=ab --password-stdin --interactive=false --overwrite=true",
 		"rt build-add-dependencies --module=backend_module --project=backend_project --spec=spec.json --server-id=tmpServerId t2 v1.0",
-		"rt build-publish t2 v1.0",
+		"rt build-publish t2 v1.0 --server-id=tmpServerId",
 	}
 
 	for i, cmd := range cmdList {
@@ -121,3 +125,32 @@ func TestAddDependenciesCommandUserPassword(t *testing.T) {
 		}
 	}
 }
+
+func TestAddDependenciesCommandServerId(t *testing.T) {
+	args := Args{
+		AccessToken: RtAccessToken,
+		Command:     "add-build-dependencies",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+		URL:         RtUrlTestStr,
+		ServerId:    "deps_server",
+		SpecPath:    "spec.json",
+	}
+	cmdList, err := GetAddDependenciesCommandArgs(args)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	wantCmds := []string{
+		"config add deps_server --url=https://artifactory.test.io/artifactory/ --access-token-stdin --interactive=false --overwrite=true",
+		"rt build-add-dependencies --server-id=deps_server --spec=spec.json t2 v1.0",
+		"rt build-publish t2 v1.0 --server-id=deps_server",
+	}
+
+	for i, cmd := range cmdList {
+		cmdStr := strings.Join(cmd, " ")
+		if cmdStr != wantCmds[i] {
+			t.Errorf("Expected: |%s|, Got: |%s|", wantCmds[i], cmdStr)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// uploadCommandName is the command run when no build tool or command is set.
+const uploadCommandName = "upload"
+
+func init() {
+	RegisterRtCommand("", uploadCommandName, uploadCommand)
+}
+
+// uploadCommand uploads the files of PLUGIN_SOURCE or PLUGIN_SPEC to the
+// server of the step and to the servers of PLUGIN_SERVERS.
+var uploadCommand = rtCommand{
+	rules: settingRules{
+		auth:         true,
+		oneOf:        [][]string{{"PLUGIN_SPEC", "PLUGIN_SOURCE"}},
+		exclusive:    [][]string{{"PLUGIN_SPEC", "PLUGIN_SOURCE"}},
+		flagMaps:     [][]JsonTagToExeFlagMapStringItem{UploadCmdJsonTagToExeFlagMapStringItemList},
+		nativeClient: true,
+	},
+	validate: func(args Args) []string {
+		if args.Source != "" && args.Target == "" {
+			return []string{"missing PLUGIN_TARGET"}
+		}
+		problems := checkServers(args)
+		problems = append(problems, checkUploadOptions(args)...)
+		problems = append(problems, checkVerify(args)...)
+		if args.Spec != "" {
+			problems = append(problems, checkSpec("PLUGIN_SPEC", args.Spec, args.SpecVars, specUpload, args.Pipeline)...)
+		}
+		return problems
+	},
+	postCommands: uploadPostCommands,
+	run:          runUpload,
+}
+
+// runUpload uploads with the client of PLUGIN_CLIENT rather than a list of jf
+// commands, as the upload is mirrored, verified and recorded.
+func runUpload(ctx context.Context, args Args) error {
+	if args.Spec != "" {
+		var err error
+		if args.Spec, err = materializeSpec(args.workspace, args.Spec, args.Pipeline); err != nil {
+			return err
+		}
+	}
+	return uploadToServers(ctx, args)
+}
+
+// UploadCmdJsonTagToExeFlagMapStringItemList maps the options of PLUGIN_SOURCE
+// uploads, spec uploads set them in the spec.
+var UploadCmdJsonTagToExeFlagMapStringItemList = []JsonTagToExeFlagMapStringItem{
+	{"--exclusions=", "PLUGIN_EXCLUSIONS", false, false},
+	{"--recursive=", "PLUGIN_RECURSIVE", false, false},
+	{"--regexp=", "PLUGIN_REGEXP", false, false},
+	{"--explode=", "PLUGIN_EXPLODE", false, false},
+	{"--symlinks=", "PLUGIN_SYMLINKS", false, false},
+	{"--include-dirs=", "PLUGIN_INCLUDE_DIRS", false, false},
+	{"--archive=", "PLUGIN_ARCHIVE", false, false},
+	{"--sync-deletes=", "PLUGIN_SYNC_DELETES", false, false},
+}
+
+// checkUploadOptions returns the problems of the upload options.
+func checkUploadOptions(args Args) []string {
+	var problems []string
+	var set []string
+	for _, item := range UploadCmdJsonTagToExeFlagMapStringItemList {
+		if value, _ := GetFieldFlagValue(&args, item.PluginArgJsonTag); value != "" {
+			set = append(set, item.PluginArgJsonTag)
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	if args.Spec != "" {
+		problems = append(problems, fmt.Sprintf("%s cannot be used with PLUGIN_SPEC, set them in the spec instead",
+			strings.Join(set, ", ")))
+	}
+	if args.Client == ClientNative {
+		problems = append(problems, fmt.Sprintf("the native client does not support %s", strings.Join(set, ", ")))
+	}
+
+	for _, option := range []struct{ setting, value string }{
+		{"PLUGIN_RECURSIVE", args.Recursive},
+		{"PLUGIN_REGEXP", args.Regexp},
+		{"PLUGIN_EXPLODE", args.Explode},
+		{"PLUGIN_SYMLINKS", args.Symlinks},
+		{"PLUGIN_INCLUDE_DIRS", args.IncludeDirs},
+	} {
+		if option.value == "" {
+			continue
+		}
+		if _, err := strconv.ParseBool(option.value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be true or false, got %q", option.setting, option.value))
+		}
+	}
+	if args.Archive != "" && args.Archive != "zip" {
+		problems = append(problems, fmt.Sprintf("PLUGIN_ARCHIVE must be zip, got %q", args.Archive))
+	}
+	if args.Archive != "" && parseBoolOrDefault(false, args.Explode) {
+		problems = append(problems, "PLUGIN_ARCHIVE and PLUGIN_EXPLODE cannot be set together")
+	}
+	return problems
+}
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestUploadCommandAlias(t *testing.T) {
+	args := Args{
+		DryRun:      true,
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		Source:      "dist/*.tgz",
+		Target:      "repo/dir/",
+		Regexp:      "false",
+		BuildName:   RtBuildName,
+		BuildNumber: RtBuildNumber,
+	}
+
+	legacy, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	args.Command = "upload"
+	explicit, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := "+ jf rt u --url=https://artifactory.test.io/artifactory/ --server-id=tmpServerId --flat=false " +
+		"--build-number=v1.0 --build-name=t2 --regexp=false dist/*.tgz repo/dir/"
+	if !strings.Contains(explicit, want) {
+		t.Errorf("Expected: %s, Got: %s", want, explicit)
+	}
+	if legacy != explicit {
+		t.Errorf("Expected the legacy upload to match PLUGIN_COMMAND=upload:\n%s\n%s", legacy, explicit)
+	}
+}
+
+func TestUploadCommandValidation(t *testing.T) {
+	_, err := GetRtCommandsList(Args{Command: "upload", URL: RtUrlTestStr, AccessToken: RtAccessToken})
+	want := "invalid settings for upload: one of PLUGIN_SPEC, PLUGIN_SOURCE must be set"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+}
+
+func TestDryRunUploadOptions(t *testing.T) {
+	args := Args{
+		DryRun:      true,
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		Source:      "dist/(.*).tgz",
+		Target:      "repo/{1}/",
+		Regexp:      "true",
+		Recursive:   "false",
+		Exclusions:  "*.tmp;*.log",
+		IncludeDirs: "true",
+		SyncDeletes: "repo/",
+	}
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "--flat=false --exclusions=*.tmp;*.log --recursive=false --regexp=true --include-dirs=true " +
+		"--sync-deletes=repo/ dist/(.*).tgz repo/{1}/"
+	if !strings.Contains(out, want) {
+		t.Errorf("Expected: %s, Got: %s", want, out)
+	}
+}
+
+func TestCheckUploadOptions(t *testing.T) {
+	tests := []struct {
+		args Args
+		want string
+	}{
+		{Args{Source: "a", Explode: "true", Symlinks: "false"}, ""},
+		{Args{Source: "a", Recursive: "yes", Archive: "tar"},
+			`PLUGIN_RECURSIVE must be true or false, got "yes"; PLUGIN_ARCHIVE must be zip, got "tar"`},
+		{Args{Source: "a", Archive: "zip", Explode: "true"}, "PLUGIN_ARCHIVE and PLUGIN_EXPLODE cannot be set together"},
+		{Args{Spec: "spec.json", Regexp: "true"}, "PLUGIN_REGEXP cannot be used with PLUGIN_SPEC, set them in the spec instead"},
+		{Args{Source: "a", Client: ClientNative, Exclusions: "*.tmp"},
+			"the native client does not support PLUGIN_EXCLUSIONS"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(checkUploadOptions(tt.args), "; "); got != tt.want {
+			t.Errorf("Expected: %s, Got: %s", tt.want, got)
+		}
+	}
+}
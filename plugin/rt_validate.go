@@ -0,0 +1,170 @@
+package plugin
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// settingRules declares the settings a command depends on, by their
+// PLUGIN_* names.
+type settingRules struct {
+	// auth requires PLUGIN_URL and one of the supported credentials.
+	auth bool
+	// required lists the settings that must be set.
+	required []string
+	// oneOf lists groups of settings of which at least one must be set.
+	oneOf [][]string
+	// exclusive lists groups of settings of which at most one may be set.
+	exclusive [][]string
+	// flagMaps are checked for items marked as mandatory.
+	flagMaps [][]JsonTagToExeFlagMapStringItem
+	// nativeClient allows PLUGIN_CLIENT=native, other commands only run jf.
+	nativeClient bool
+}
+
+// check returns every missing or conflicting setting.
+func (r settingRules) check(args Args) []string {
+	var problems []string
+
+	if r.auth {
+		if !isSet(&args, "PLUGIN_URL") {
+			problems = append(problems, "missing PLUGIN_URL")
+		}
+		if args.APIKey != "" && args.Username == "" {
+			// jf only takes an api key as the password of a user
+			problems = append(problems, "PLUGIN_API_KEY requires PLUGIN_USERNAME")
+		} else if !hasCredentials(args) {
+			problems = append(problems, "missing credentials, set PLUGIN_USERNAME and PLUGIN_PASSWORD "+
+				"or PLUGIN_API_KEY, or PLUGIN_ACCESS_TOKEN")
+		}
+	}
+
+	required := append([]string{}, r.required...)
+	for _, flagMap := range r.flagMaps {
+		for _, item := range flagMap {
+			if item.IsMandatory {
+				required = append(required, item.PluginArgJsonTag)
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for _, tag := range required {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		if !hasSetting(tag) {
+			problems = append(problems, fmt.Sprintf("unknown setting %s", tag))
+		} else if !isSet(&args, tag) {
+			problems = append(problems, fmt.Sprintf("missing %s", tag))
+		}
+	}
+
+	for _, group := range r.oneOf {
+		if len(setSettings(&args, group)) == 0 {
+			problems = append(problems, fmt.Sprintf("one of %s must be set", strings.Join(group, ", ")))
+		}
+	}
+
+	for _, group := range r.exclusive {
+		if set := setSettings(&args, group); len(set) > 1 {
+			problems = append(problems, fmt.Sprintf("%s cannot be set together", strings.Join(set, " and ")))
+		}
+	}
+
+	switch args.Client {
+	case "", ClientJf:
+	case ClientNative:
+		if !r.nativeClient {
+			problems = append(problems, "PLUGIN_CLIENT=native is only supported by upload and download")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("PLUGIN_CLIENT must be %s or %s, got %q", ClientJf, ClientNative, args.Client))
+	}
+
+	return problems
+}
+
+// checkPublishBuildInfo returns the problems preventing publishBuildInfo from
+// running when PLUGIN_PUBLISH_BUILD_INFO is set.
+func checkPublishBuildInfo(args Args) []string {
+	if !args.PublishBuildInfo {
+		return nil
+	}
+	var problems []string
+	if args.BuildName == "" || args.BuildNumber == "" {
+		problems = append(problems, "PLUGIN_PUBLISH_BUILD_INFO requires PLUGIN_BUILD_NAME and PLUGIN_BUILD_NUMBER")
+	}
+	if args.AccessToken == "" && (args.Username == "" || args.Password == "") {
+		problems = append(problems, "PLUGIN_PUBLISH_BUILD_INFO requires PLUGIN_ACCESS_TOKEN or "+
+			"PLUGIN_USERNAME and PLUGIN_PASSWORD")
+	}
+	return problems
+}
+
+// SettingsError lists every invalid setting of a command.
+type SettingsError struct {
+	Command  string
+	Problems []string
+}
+
+func (e *SettingsError) Error() string {
+	return fmt.Sprintf("invalid settings for %s: %s", e.Command, strings.Join(e.Problems, "; "))
+}
+
+// CheckFlagMaps verifies that every flag map of the registered commands
+// references a PLUGIN_* setting Args has a field for, and that the field can
+// be formatted as a flag value.
+func CheckFlagMaps() error {
+	var problems []string
+	for key, handler := range rtCommandRegistry {
+		command, ok := handler.(rtCommand)
+		if !ok {
+			continue
+		}
+		for _, flagMap := range command.rules.flagMaps {
+			for _, item := range flagMap {
+				if _, err := GetFieldFlagValue(&Args{}, item.PluginArgJsonTag); err != nil {
+					problems = append(problems, fmt.Sprintf("%s flag %s: %s", key, item.FlagName, err))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid flag maps: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// hasCredentials reports whether setAuthParams can authenticate with args.
+func hasCredentials(args Args) bool {
+	return (args.Username != "" && (args.Password != "" || args.APIKey != "")) || args.AccessToken != ""
+}
+
+// hasSetting reports whether Args has a field for the PLUGIN_* setting.
+func hasSetting(tag string) bool {
+	_, found := getTagMapping(reflect.TypeOf(Args{}))[tag]
+	return found
+}
+
+// isSet reports whether the setting holds a non zero value.
+func isSet(args *Args, tag string) bool {
+	index, found := getTagMapping(reflect.TypeOf(*args))[tag]
+	if !found {
+		return false
+	}
+	return !reflect.ValueOf(args).Elem().Field(index).IsZero()
+}
+
+func setSettings(args *Args, tags []string) []string {
+	var set []string
+	for _, tag := range tags {
+		if isSet(args, tag) {
+			set = append(set, tag)
+		}
+	}
+	return set
+}
@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidatePromoteListsAllProblems(t *testing.T) {
+	_, err := GetRtCommandsList(Args{Command: "promote", Username: "ab"})
+	if err == nil {
+		t.Fatalf("Expected a validation error")
+	}
+
+	want := "invalid settings for promote: missing PLUGIN_URL; " +
+		"missing credentials, set PLUGIN_USERNAME and PLUGIN_PASSWORD or PLUGIN_API_KEY, or PLUGIN_ACCESS_TOKEN; " +
+		"missing PLUGIN_BUILD_NAME; missing PLUGIN_BUILD_NUMBER; missing PLUGIN_TARGET"
+	if err.Error() != want {
+		t.Errorf("Expected: %s\nGot:      %s", want, err)
+	}
+}
+
+func TestValidateDownloadSpecConflict(t *testing.T) {
+	args := Args{
+		Command:     "download",
+		URL:         RtUrlTestStr,
+		AccessToken: RtAccessToken,
+		Spec:        `{"files":[]}`,
+		SpecPath:    "spec.json",
+	}
+	_, err := GetRtCommandsList(args)
+	want := "invalid settings for download: PLUGIN_SPEC and PLUGIN_SPEC_PATH cannot be set together"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+
+	args.Spec, args.SpecPath = "", ""
+	_, err = GetRtCommandsList(args)
+	want = "invalid settings for download: one of PLUGIN_SPEC, PLUGIN_SPEC_PATH, PLUGIN_SOURCE must be set"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+}
+
+func TestValidateMandatoryFlagMapItems(t *testing.T) {
+	rules := settingRules{
+		flagMaps: [][]JsonTagToExeFlagMapStringItem{{
+			{"--module=", "PLUGIN_MODULE", true, false},
+			{"--project=", "PLUGIN_PROJECT", false, false},
+			{"--unknown=", "PLUGIN_UNKNOWN", true, false},
+		}},
+	}
+	problems := rules.check(Args{})
+	if len(problems) != 2 || problems[0] != "missing PLUGIN_MODULE" || problems[1] != "unknown setting PLUGIN_UNKNOWN" {
+		t.Errorf("Unexpected problems: %q", problems)
+	}
+
+	cmdArgs := []string{"rt", "download"}
+	err := PopulateArgs(&cmdArgs, &Args{}, rules.flagMaps[0][:1])
+	if err == nil || err.Error() != "missing mandatory setting PLUGIN_MODULE" {
+		t.Errorf("Expected missing mandatory setting error, Got: %v", err)
+	}
+}
+
+func TestValidateAPIKeyRequiresUsername(t *testing.T) {
+	_, err := GetRtCommandsList(Args{Command: "upload", URL: RtUrlTestStr, APIKey: "secretkey", Source: "a.txt", Target: "repo/"})
+	want := "invalid settings for upload: PLUGIN_API_KEY requires PLUGIN_USERNAME"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+}
+
+func TestValidateUpload(t *testing.T) {
+	err := Exec(context.Background(), Args{
+		URL: RtUrlTestStr, Username: "john", APIKey: "secretkey", Source: "a.txt", PublishBuildInfo: true,
+	})
+	want := "invalid settings for upload: " +
+		"PLUGIN_PUBLISH_BUILD_INFO requires PLUGIN_BUILD_NAME and PLUGIN_BUILD_NUMBER; " +
+		"PLUGIN_PUBLISH_BUILD_INFO requires PLUGIN_ACCESS_TOKEN or PLUGIN_USERNAME and PLUGIN_PASSWORD; " +
+		"missing PLUGIN_TARGET"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s\nGot:      %v", want, err)
+	}
+}
+
+func TestValidateClient(t *testing.T) {
+	tests := []struct {
+		args Args
+		want string
+	}{
+		{Args{Command: "upload", Client: ClientNative, Source: "a.txt", Target: "repo/"}, ""},
+		{Args{Command: "download", Client: ClientJf, Source: "repo/a.txt"}, ""},
+		{Args{Command: "download", Client: ClientNative, Source: "repo/a.txt"}, ""},
+		{Args{Command: "download", Client: ClientNative, Spec: `{"files": [{"pattern": "repo/*"}]}`},
+			"invalid settings for download: the native client does not support PLUGIN_SPEC"},
+		{Args{Command: "promote", Client: ClientNative, Target: "release", BuildName: RtBuildName, BuildNumber: RtBuildNumber},
+			"invalid settings for promote: PLUGIN_CLIENT=native is only supported by upload and download"},
+		{Args{Command: "upload", Client: "rest", Source: "a.txt", Target: "repo/"},
+			`invalid settings for upload: PLUGIN_CLIENT must be jf or native, got "rest"`},
+	}
+	for _, tt := range tests {
+		tt.args.URL, tt.args.AccessToken = RtUrlTestStr, RtAccessToken
+		_, err := GetRtCommandsList(tt.args)
+		if got := fmt.Sprint(err); (tt.want == "" && err != nil) || (tt.want != "" && got != tt.want) {
+			t.Errorf("Expected: %s, Got: %v", tt.want, err)
+		}
+	}
+}
+
+func TestCheckFlagMaps(t *testing.T) {
+	if err := CheckFlagMaps(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	RegisterRtCommand("", "test-flag-maps", rtCommand{
+		rules: settingRules{
+			flagMaps: [][]JsonTagToExeFlagMapStringItem{{
+				{"--missing=", "PLUGIN_MISSING", false, false},
+				{"--timeout=", "PLUGIN_TIMEOUT", false, false},
+				{"--pipeline=", "PLUGIN_PIPELINE_MISSING", false, false},
+			}},
+		},
+	})
+	defer delete(rtCommandRegistry, rtCommandKey{command: "test-flag-maps"})
+
+	err := CheckFlagMaps()
+	if err == nil {
+		t.Fatalf("Expected an error for the unknown settings")
+	}
+	for _, want := range []string{"--missing=", "--pipeline="} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %s to be reported, Got: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "--timeout=") {
+		t.Errorf("Expected durations to be accepted, Got: %v", err)
+	}
+}
+
+func TestPopulateArgsNonStringFields(t *testing.T) {
+	cmdArgs := []string{"mvn"}
+	flags := []JsonTagToExeFlagMapStringItem{
+		{"--threads=", "PLUGIN_THREADS", false, false},
+		{"--retries=", "PLUGIN_RETRIES", false, false},
+		{"--dry-run=", "PLUGIN_DRY_RUN", false, false},
+	}
+	if err := PopulateArgs(&cmdArgs, &Args{Threads: 8, DryRun: true}, flags); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "mvn --threads=8 --dry-run=true"
+	if got := strings.Join(cmdArgs, " "); got != want {
+		t.Errorf("Expected: %s, Got: %s", want, got)
+	}
+}
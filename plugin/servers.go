@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ServersPolicyAll             = "all"
+	ServersPolicyAny             = "any"
+	ServersPolicyPrimaryRequired = "primary-required"
+
+	primaryServerName = "primary"
+)
+
+// Server is an additional Artifactory instance uploads are mirrored to. The
+// credentials are referenced by the name of the environment variable holding
+// them, so secrets are not copied into the settings.
+type Server struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	Username       string `json:"username"`
+	PasswordEnv    string `json:"password_env"`
+	APIKeyEnv      string `json:"api_key_env"`
+	AccessTokenEnv string `json:"access_token_env"`
+}
+
+// ServerResult is the outcome of the upload to one server.
+type ServerResult struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// parseServers parses PLUGIN_SERVERS, a JSON list of servers.
+func parseServers(servers string) ([]Server, error) {
+	if strings.TrimSpace(servers) == "" {
+		return nil, nil
+	}
+	var parsed []Server
+	if err := json.Unmarshal([]byte(servers), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %s", err)
+	}
+	for i := range parsed {
+		if parsed[i].Name == "" {
+			parsed[i].Name = fmt.Sprintf("server-%d", i+1)
+		}
+	}
+	return parsed, nil
+}
+
+// apply returns args with the URL and credentials of the server, and a
+// manifest file of its own.
+func (s Server) apply(args Args) Args {
+	args.URL = s.URL
+	args.Username = s.Username
+	args.Password = lookupEnv(s.PasswordEnv)
+	args.APIKey = lookupEnv(s.APIKeyEnv)
+	args.AccessToken = lookupEnv(s.AccessTokenEnv)
+	args.serverName = s.Name
+	if args.ManifestFile != "" {
+		args.ManifestFile = serverManifestFile(args.ManifestFile, s.Name)
+	}
+	return args
+}
+
+func (s Server) secrets() []string {
+	return []string{lookupEnv(s.PasswordEnv), lookupEnv(s.APIKeyEnv), lookupEnv(s.AccessTokenEnv)}
+}
+
+func lookupEnv(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
+
+// checkServers returns the problems of PLUGIN_SERVERS and PLUGIN_SERVERS_POLICY.
+func checkServers(args Args) []string {
+	var problems []string
+	switch args.ServersPolicy {
+	case "", ServersPolicyAll, ServersPolicyAny, ServersPolicyPrimaryRequired:
+	default:
+		problems = append(problems, fmt.Sprintf("PLUGIN_SERVERS_POLICY must be one of %s, %s or %s, got %q",
+			ServersPolicyAll, ServersPolicyAny, ServersPolicyPrimaryRequired, args.ServersPolicy))
+	}
+
+	servers, err := parseServers(args.Servers)
+	if err != nil {
+		return append(problems, fmt.Sprintf("PLUGIN_SERVERS: %s", err))
+	}
+	for i, server := range servers {
+		prefix := fmt.Sprintf("PLUGIN_SERVERS[%d]", i)
+		if server.URL == "" {
+			problems = append(problems, prefix+": missing url")
+		}
+		for _, env := range []string{server.PasswordEnv, server.APIKeyEnv, server.AccessTokenEnv} {
+			if env != "" && lookupEnv(env) == "" {
+				problems = append(problems, fmt.Sprintf("%s: environment variable %s is not set", prefix, env))
+			}
+		}
+		serverArgs := server.apply(args)
+		if serverArgs.APIKey != "" && serverArgs.Username == "" {
+			problems = append(problems, prefix+": api_key_env requires username")
+		} else if !hasCredentials(serverArgs) {
+			problems = append(problems, prefix+": missing credentials, set username and password_env "+
+				"or api_key_env, or access_token_env")
+		} else if args.PublishBuildInfo && serverArgs.AccessToken == "" && serverArgs.Password == "" {
+			problems = append(problems, prefix+": PLUGIN_PUBLISH_BUILD_INFO requires access_token_env or "+
+				"username and password_env")
+		}
+	}
+	return problems
+}
+
+// uploadPostCommands returns the post actions of an upload, run against
+// every server the upload is mirrored to. Each action runs for all servers
+// before the next one, so the local build-info is published everywhere
+// before build-clean removes it. The native client publishes the build-info
+// with its uploads, see upload.
+func uploadPostCommands(args Args) ([][]string, error) {
+	servers, err := parseServers(args.Servers)
+	if err != nil {
+		return nil, err
+	}
+	targets := []Args{args}
+	for _, server := range servers {
+		targets = append(targets, server.apply(args))
+	}
+
+	var cmdList [][]string
+	for _, action := range postActions {
+		if action.name == postActionBuildPublish && args.Client == ClientNative {
+			continue
+		}
+		for i, target := range targets {
+			if i > 0 && action.name == postActionBuildClean {
+				// build-clean only removes the local build-info, once is enough
+				break
+			}
+			commands, err := action.postCommands(target)
+			if err != nil && i > 0 {
+				return nil, fmt.Errorf("server %s: %s", servers[i-1].Name, err)
+			} else if err != nil {
+				return nil, err
+			}
+			cmdList = append(cmdList, commands...)
+		}
+	}
+	return cmdList, nil
+}
+
+// uploadToServers uploads to the server of args and to every server of
+// PLUGIN_SERVERS, one after the other, and applies PLUGIN_SERVERS_POLICY to
+// the outcomes.
+func uploadToServers(ctx context.Context, args Args) error {
+	servers, err := parseServers(args.Servers)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		return upload(ctx, args)
+	}
+
+	targets := []struct {
+		name string
+		args Args
+	}{{primaryServerName, args}}
+	for _, server := range servers {
+		targets = append(targets, struct {
+			name string
+			args Args
+		}{server.Name, server.apply(args)})
+	}
+
+	results := make([]ServerResult, 0, len(targets))
+	for _, target := range targets {
+		logrus.Printf("Uploading to server %s at %s\n", target.name, target.args.URL)
+		result := ServerResult{Name: target.name, URL: target.args.URL, Status: ResultSuccess}
+		if err := upload(ctx, target.args); err != nil {
+			result.Status = ResultFailure
+			result.Error = newRedactor(args).redact(err.Error())
+			logrus.Printf("Upload to server %s failed: %s\n", target.name, result.Error)
+		}
+		results = append(results, result)
+		args.result.addServer(result)
+
+		// nothing else can run once the step is cancelled or timed out
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return applyServersPolicy(args.ServersPolicy, results, len(targets))
+}
+
+// applyServersPolicy returns an error when the server results do not satisfy
+// the policy: all servers, any server or the primary server succeeded.
+func applyServersPolicy(policy string, results []ServerResult, servers int) error {
+	var failed []string
+	for _, result := range results {
+		if result.Status != ResultSuccess {
+			failed = append(failed, fmt.Sprintf("%s: %s", result.Name, result.Error))
+		}
+	}
+	succeeded := len(results) - len(failed)
+
+	var ok bool
+	switch policy {
+	case ServersPolicyAny:
+		ok = succeeded > 0
+	case ServersPolicyPrimaryRequired:
+		ok = len(results) > 0 && results[0].Status == ResultSuccess
+	default:
+		ok = succeeded == servers
+	}
+
+	if !ok {
+		if len(failed) == 0 {
+			return fmt.Errorf("upload did not run on all %d servers", servers)
+		}
+		return fmt.Errorf("upload failed on %d of %d servers: %s", len(failed), servers, strings.Join(failed, "; "))
+	}
+	if len(failed) > 0 {
+		logrus.Printf("Upload failed on %d of %d servers, allowed by the %s policy\n", len(failed), servers, policy)
+	}
+	return nil
+}
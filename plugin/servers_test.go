@@ -0,0 +1,162 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckServers(t *testing.T) {
+	t.Setenv("EU_TOKEN", "eu-token")
+	t.Setenv("EMPTY_PASSWORD", "")
+
+	args := Args{
+		ServersPolicy:    "some",
+		PublishBuildInfo: true,
+		Servers: `[
+			{"name": "eu", "url": "https://eu.test.io/artifactory/", "access_token_env": "EU_TOKEN"},
+			{"username": "ci", "password_env": "EMPTY_PASSWORD"},
+			{"url": "https://us.test.io/artifactory/", "username": "ci", "api_key_env": "EU_TOKEN"},
+			{"url": "https://ap.test.io/artifactory/", "api_key_env": "EU_TOKEN"}
+		]`,
+	}
+	want := []string{
+		`PLUGIN_SERVERS_POLICY must be one of all, any or primary-required, got "some"`,
+		"PLUGIN_SERVERS[1]: missing url",
+		"PLUGIN_SERVERS[1]: environment variable EMPTY_PASSWORD is not set",
+		"PLUGIN_SERVERS[1]: missing credentials, set username and password_env or api_key_env, or access_token_env",
+		"PLUGIN_SERVERS[2]: PLUGIN_PUBLISH_BUILD_INFO requires access_token_env or username and password_env",
+		"PLUGIN_SERVERS[3]: api_key_env requires username",
+	}
+	if problems := checkServers(args); !reflect.DeepEqual(problems, want) {
+		t.Errorf("Expected: %q\nGot:      %q", want, problems)
+	}
+
+	problems := checkServers(Args{Servers: `{"url": "a"}`})
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], "PLUGIN_SERVERS: invalid JSON") {
+		t.Errorf("Unexpected problems: %q", problems)
+	}
+}
+
+func TestApplyServersPolicy(t *testing.T) {
+	ok := ServerResult{Name: "primary", Status: ResultSuccess}
+	failed := ServerResult{Name: "eu", Status: ResultFailure, Error: "exit status 1"}
+	failedPrimary := ServerResult{Name: "primary", Status: ResultFailure, Error: "exit status 1"}
+
+	tests := []struct {
+		policy  string
+		results []ServerResult
+		err     error
+	}{
+		{"", []ServerResult{ok, ok}, nil},
+		{ServersPolicyAll, []ServerResult{ok, failed}, errors.New("upload failed on 1 of 2 servers: eu: exit status 1")},
+		{ServersPolicyAny, []ServerResult{failedPrimary, ok}, nil},
+		{ServersPolicyAny, []ServerResult{failedPrimary, failed},
+			errors.New("upload failed on 2 of 2 servers: primary: exit status 1; eu: exit status 1")},
+		{ServersPolicyPrimaryRequired, []ServerResult{ok, failed}, nil},
+		{ServersPolicyPrimaryRequired, []ServerResult{failedPrimary, ok},
+			errors.New("upload failed on 1 of 2 servers: primary: exit status 1")},
+		{ServersPolicyAll, []ServerResult{ok}, errors.New("upload did not run on all 2 servers")},
+	}
+
+	for _, tc := range tests {
+		err := applyServersPolicy(tc.policy, tc.results, 2)
+		if !reflect.DeepEqual(err, tc.err) && (err == nil || tc.err == nil || err.Error() != tc.err.Error()) {
+			t.Errorf("For policy %q, Expected: %v, Got: %v", tc.policy, tc.err, err)
+		}
+	}
+}
+
+func TestUploadToServers(t *testing.T) {
+	dir := t.TempDir()
+	fakeJf := `#!/bin/sh
+case "$*" in
+*eu.test.io*) echo "upload failed" >&2; exit 1 ;;
+esac
+`
+	installFakeJf(t, fakeJf)
+	t.Setenv("EU_TOKEN", "eu-token")
+	t.Setenv("US_TOKEN", "us-token")
+
+	args := Args{
+		URL:         RtUrlTestStr,
+		AccessToken: RtAccessToken,
+		Source:      "a.txt",
+		Target:      "repo/",
+		Servers: `[{"name": "eu", "url": "https://eu.test.io/artifactory/", "access_token_env": "EU_TOKEN"},
+			{"name": "us", "url": "https://us.test.io/artifactory/", "access_token_env": "US_TOKEN"}]`,
+		ResultFile: filepath.Join(dir, "result.json"),
+	}
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	want := "upload failed on 1 of 3 servers: eu: exit status 1"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+	for serverId, url := range map[string]string{
+		"tmpServerId":    RtUrlTestStr,
+		"tmpServerId-eu": "https://eu.test.io/artifactory/",
+		"tmpServerId-us": "https://us.test.io/artifactory/",
+	} {
+		if !strings.Contains(out, "jf config add "+serverId+" --url="+url) {
+			t.Errorf("Expected an upload to %s, Got: %s", url, out)
+		}
+	}
+
+	args.ServersPolicy = ServersPolicyPrimaryRequired
+	if _, err := captureStdout(t, func() error { return Exec(context.Background(), args) }); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(args.ResultFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"name": "eu",`) || !strings.Contains(string(data), `"status": "failure"`) {
+		t.Errorf("Expected the server results, Got: %s", data)
+	}
+}
+
+func TestDryRunUploadToServersPostActions(t *testing.T) {
+	t.Setenv("EU_TOKEN", "eu-token")
+	t.Setenv("US_TOKEN", "us-token")
+
+	args := Args{
+		DryRun:           true,
+		URL:              RtUrlTestStr,
+		AccessToken:      RtAccessToken,
+		Source:           "a.txt",
+		Target:           "repo/",
+		BuildName:        RtBuildName,
+		BuildNumber:      RtBuildNumber,
+		PublishBuildInfo: true,
+		MaxBuilds:        "5",
+		Servers: `[{"name": "eu", "url": "https://eu.test.io/artifactory/", "access_token_env": "EU_TOKEN"},
+			{"name": "us", "url": "https://us.test.io/artifactory/", "access_token_env": "US_TOKEN"}]`,
+	}
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, serverId := range []string{"tmpServerIdbpi", "tmpServerIdbpi-eu", "tmpServerIdbpi-us"} {
+		if count := strings.Count(out, "jf rt build-publish t2 v1.0 --server-id="+serverId+"\n"); count != 1 {
+			t.Errorf("Expected one build-info publish to %s, Got %d: %s", serverId, count, out)
+		}
+	}
+	for serverId, url := range map[string]string{
+		"tmpServerIdbdi":    RtUrlTestStr,
+		"tmpServerIdbdi-eu": "https://eu.test.io/artifactory/",
+		"tmpServerIdbdi-us": "https://us.test.io/artifactory/",
+	} {
+		if !strings.Contains(out, "jf config add "+serverId+" --url="+url) {
+			t.Errorf("Expected a build-discard server config for %s, Got: %s", url, out)
+		}
+		if !strings.Contains(out, "jf rt build-discard --server-id="+serverId+" --max-builds=5 t2") {
+			t.Errorf("Expected a build-discard on %s, Got: %s", url, out)
+		}
+	}
+}
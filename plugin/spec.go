@@ -0,0 +1,329 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	specUpload   = "upload"
+	specDownload = "download"
+)
+
+// FileSpec is a JFrog file spec, describing the files an upload, download
+// or build-add-dependencies command acts on.
+type FileSpec struct {
+	Files []FileSpecEntry `json:"files"`
+}
+
+// FileSpecEntry is a single entry of a FileSpec. Boolean options are strings,
+// as in the JFrog CLI.
+type FileSpecEntry struct {
+	Pattern                 string          `json:"pattern,omitempty"`
+	Aql                     json.RawMessage `json:"aql,omitempty"`
+	PathMapping             json.RawMessage `json:"pathMapping,omitempty"`
+	Target                  string          `json:"target,omitempty"`
+	Props                   string          `json:"props,omitempty"`
+	TargetProps             string          `json:"targetProps,omitempty"`
+	ExcludeProps            string          `json:"excludeProps,omitempty"`
+	Exclusions              []string        `json:"exclusions,omitempty"`
+	ExcludePatterns         []string        `json:"excludePatterns,omitempty"`
+	Recursive               string          `json:"recursive,omitempty"`
+	Flat                    string          `json:"flat,omitempty"`
+	Explode                 string          `json:"explode,omitempty"`
+	BypassArchiveInspection string          `json:"bypassArchiveInspection,omitempty"`
+	Regexp                  string          `json:"regexp,omitempty"`
+	Ant                     string          `json:"ant,omitempty"`
+	IncludeDirs             string          `json:"includeDirs,omitempty"`
+	Symlinks                string          `json:"symlinks,omitempty"`
+	ValidateSymlinks        string          `json:"validateSymlinks,omitempty"`
+	Archive                 string          `json:"archive,omitempty"`
+	ArchiveEntries          string          `json:"archiveEntries,omitempty"`
+	TargetPathInArchive     string          `json:"targetPathInArchive,omitempty"`
+	Build                   string          `json:"build,omitempty"`
+	Bundle                  string          `json:"bundle,omitempty"`
+	Project                 string          `json:"project,omitempty"`
+	ExcludeArtifacts        string          `json:"excludeArtifacts,omitempty"`
+	IncludeDeps             string          `json:"includeDeps,omitempty"`
+	Transitive              string          `json:"transitive,omitempty"`
+	PublicGpgKey            string          `json:"publicGpgKey,omitempty"`
+	SortBy                  []string        `json:"sortBy,omitempty"`
+	SortOrder               string          `json:"sortOrder,omitempty"`
+	Offset                  int             `json:"offset,omitempty"`
+	Limit                   int             `json:"limit,omitempty"`
+}
+
+// SpecError lists the problems of a file spec, each pointing at the
+// offending file entry.
+type SpecError struct {
+	Problems []string
+}
+
+func (e *SpecError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// ParseFileSpec parses a JSON file spec, once the spec vars in the jf
+// notation key1=value1;key2=value2 are replaced. Every file entry is parsed
+// on its own so errors point at the entry. Unknown fields are only warned
+// about, jf reads the spec itself and may support them.
+func ParseFileSpec(data []byte, specVars string) (*FileSpec, error) {
+	data = replaceSpecVars(data, specVars)
+
+	var raw struct {
+		Files []json.RawMessage `json:"files"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &SpecError{Problems: []string{fmt.Sprintf("invalid JSON: %s", err)}}
+	}
+	if len(raw.Files) == 0 {
+		return nil, &SpecError{Problems: []string{"files must not be empty"}}
+	}
+
+	spec := &FileSpec{Files: make([]FileSpecEntry, len(raw.Files))}
+	var problems []string
+	for i, rawEntry := range raw.Files {
+		if err := json.Unmarshal(rawEntry, &spec.Files[i]); err != nil {
+			problems = append(problems, fmt.Sprintf("files[%d]: %s", i, strings.TrimPrefix(err.Error(), "json: ")))
+			continue
+		}
+		for _, field := range unknownSpecFields(rawEntry) {
+			logrus.Printf("Warning: files[%d]: unknown field %q, passed on to jf as is\n", i, field)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &SpecError{Problems: problems}
+	}
+	return spec, nil
+}
+
+// Validate checks the entries of the spec for an upload or a download,
+// returning every problem found.
+func (s *FileSpec) Validate(kind string) error {
+	var problems []string
+	for i, entry := range s.Files {
+		for _, problem := range entry.validate(kind) {
+			problems = append(problems, fmt.Sprintf("files[%d]: %s", i, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return &SpecError{Problems: problems}
+	}
+	return nil
+}
+
+func (e FileSpecEntry) validate(kind string) []string {
+	var problems []string
+
+	switch {
+	case e.Pattern == "" && len(e.Aql) == 0:
+		problems = append(problems, "pattern or aql must be set")
+	case e.Pattern != "" && len(e.Aql) != 0:
+		problems = append(problems, "pattern and aql cannot be set together")
+	}
+	if len(e.Aql) != 0 {
+		if kind == specUpload {
+			problems = append(problems, "aql cannot be used to upload")
+		} else if bytes.TrimSpace(e.Aql)[0] != '{' {
+			problems = append(problems, "aql must be an object")
+		}
+	}
+	if kind == specUpload && e.Target == "" {
+		problems = append(problems, "missing target")
+	}
+
+	for _, option := range []struct{ name, value string }{
+		{"recursive", e.Recursive},
+		{"flat", e.Flat},
+		{"explode", e.Explode},
+		{"bypassArchiveInspection", e.BypassArchiveInspection},
+		{"regexp", e.Regexp},
+		{"ant", e.Ant},
+		{"includeDirs", e.IncludeDirs},
+		{"symlinks", e.Symlinks},
+		{"validateSymlinks", e.ValidateSymlinks},
+		{"excludeArtifacts", e.ExcludeArtifacts},
+		{"includeDeps", e.IncludeDeps},
+		{"transitive", e.Transitive},
+	} {
+		if option.value == "" {
+			continue
+		}
+		if _, err := strconv.ParseBool(option.value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be true or false, got %q", option.name, option.value))
+		}
+	}
+	if parseBoolOrDefault(false, e.Regexp) && parseBoolOrDefault(false, e.Ant) {
+		problems = append(problems, "regexp and ant cannot be set together")
+	}
+	if e.Archive != "" && e.Archive != "zip" {
+		problems = append(problems, fmt.Sprintf("archive must be zip, got %q", e.Archive))
+	}
+	if e.SortOrder != "" && e.SortOrder != "asc" && e.SortOrder != "desc" {
+		problems = append(problems, fmt.Sprintf("sortOrder must be asc or desc, got %q", e.SortOrder))
+	}
+	if e.Offset < 0 || e.Limit < 0 {
+		problems = append(problems, "offset and limit cannot be negative")
+	}
+	return problems
+}
+
+// unknownSpecFields returns the fields of the file entry FileSpecEntry does
+// not model, such as options of newer jf versions.
+func unknownSpecFields(rawEntry json.RawMessage) []string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rawEntry, &fields); err != nil {
+		return nil
+	}
+	known := map[string]bool{}
+	entryType := reflect.TypeOf(FileSpecEntry{})
+	for i := 0; i < entryType.NumField(); i++ {
+		name, _, _ := strings.Cut(entryType.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+
+	var unknown []string
+	for field := range fields {
+		if !known[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// replaceSpecVars replaces the ${key} placeholders of the spec vars, the way
+// the JFrog CLI does before parsing the spec.
+func replaceSpecVars(data []byte, specVars string) []byte {
+	for _, pair := range strings.Split(specVars, ";") {
+		keyValue := strings.SplitN(pair, "=", 2)
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			continue
+		}
+		data = bytes.ReplaceAll(data, []byte("${"+keyValue[0]+"}"), []byte(keyValue[1]))
+	}
+	return data
+}
+
+// isInlineSpec reports whether the spec setting holds the spec itself, JSON
+// or YAML, rather than the path of a spec file.
+func isInlineSpec(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	return strings.HasPrefix(spec, "{") || strings.HasPrefix(spec, "files:") || strings.Contains(spec, "\n")
+}
+
+func isYAMLSpec(spec string) bool {
+	if isInlineSpec(spec) {
+		return !strings.HasPrefix(strings.TrimSpace(spec), "{")
+	}
+	ext := strings.ToLower(filepath.Ext(spec))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// loadSpec returns the JSON content of the spec setting, inline or read from
+// the path, with its templates rendered from the pipeline and YAML specs
+// converted.
+func loadSpec(spec string, pipeline Pipeline) ([]byte, error) {
+	data := []byte(spec)
+	if !isInlineSpec(spec) {
+		var err error
+		if data, err = os.ReadFile(spec); err != nil {
+			return nil, fmt.Errorf("cannot read spec file: %s", err)
+		}
+	}
+	rendered, err := renderTemplate("spec", string(data), pipeline, !isYAMLSpec(spec))
+	if err != nil {
+		return nil, err
+	}
+	data = []byte(rendered)
+	if !isYAMLSpec(spec) {
+		return data, nil
+	}
+
+	var content interface{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %s", err)
+	}
+	data, err = json.Marshal(yamlToJSON(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML: %s", err)
+	}
+	return data, nil
+}
+
+// yamlToJSON prepares decoded YAML for the JSON spec model, booleans are
+// strings in JFrog specs.
+func yamlToJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = yamlToJSON(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = yamlToJSON(item)
+		}
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return value
+}
+
+// materializeSpec returns the path of a JSON spec file for the spec setting.
+// JSON spec files without templates are used as they are, the other specs
+// are written to the workspace once loaded.
+func materializeSpec(ws *workspace, spec string, pipeline Pipeline) (string, error) {
+	if !isInlineSpec(spec) && !isYAMLSpec(spec) {
+		// unreadable files are reported by checkSpec
+		data, err := os.ReadFile(spec)
+		if err != nil || !bytes.Contains(data, []byte("{{")) {
+			return spec, nil
+		}
+	}
+	data, err := loadSpec(spec, pipeline)
+	if err != nil {
+		return "", err
+	}
+	return ws.writeFile("spec-*.json", data)
+}
+
+// checkSpec parses and validates the spec of the setting, inline or read from
+// the path, returning the problems prefixed with the setting name.
+func checkSpec(setting, spec, specVars, kind string, pipeline Pipeline) []string {
+	data, err := loadSpec(spec, pipeline)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %s", setting, err)}
+	}
+
+	fileSpec, err := ParseFileSpec(data, specVars)
+	if err == nil {
+		err = fileSpec.Validate(kind)
+	}
+	if err == nil {
+		return nil
+	}
+
+	specErr, ok := err.(*SpecError)
+	if !ok {
+		return []string{fmt.Sprintf("%s: %s", setting, err)}
+	}
+	problems := make([]string, len(specErr.Problems))
+	for i, problem := range specErr.Problems {
+		if strings.HasPrefix(problem, "files[") {
+			problems[i] = setting + " " + problem
+		} else {
+			problems[i] = setting + ": " + problem
+		}
+	}
+	return problems
+}
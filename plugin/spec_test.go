@@ -0,0 +1,217 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFileSpec(t *testing.T) {
+	data := []byte(`{
+  "files": [
+    {"pattern": "dist/*.zip", "target": "${repo}/app/", "props": "a=1", "recursive": "false", "exclusions": ["*.tmp"]},
+    {"aql": {"items.find": {"repo": "libs"}}, "target": "out/", "flat": "true", "explode": "true"}
+  ]
+}`)
+
+	spec, err := ParseFileSpec(data, "repo=generic-local")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := FileSpecEntry{Pattern: "dist/*.zip", Target: "generic-local/app/", Props: "a=1", Recursive: "false",
+		Exclusions: []string{"*.tmp"}}
+	if !reflect.DeepEqual(spec.Files[0], want) {
+		t.Errorf("Expected: %+v, Got: %+v", want, spec.Files[0])
+	}
+	if string(spec.Files[1].Aql) != `{"items.find": {"repo": "libs"}}` || spec.Files[1].Explode != "true" {
+		t.Errorf("Unexpected entry: %+v", spec.Files[1])
+	}
+	if err := spec.Validate(specDownload); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := spec.Validate(specUpload); err == nil || err.Error() != "files[1]: aql cannot be used to upload" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestParseFileSpecErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{`{"files": [`, "invalid JSON: unexpected end of JSON input"},
+		{`{"files": []}`, "files must not be empty"},
+		{`{"files": [{"pattern": "a", "flat": true}]}`,
+			"files[0]: cannot unmarshal bool into Go struct field FileSpecEntry.flat of type string"},
+	}
+
+	for _, tc := range tests {
+		_, err := ParseFileSpec([]byte(tc.spec), "")
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("For %s, Expected: %s, Got: %v", tc.spec, tc.err, err)
+		}
+	}
+}
+
+func TestParseFileSpecUnknownFields(t *testing.T) {
+	spec, err := ParseFileSpec([]byte(`{"files": [{"pattern": "a/*", "target": "repo/", `+
+		`"excludePatterns": ["*.tmp"], "newOption": "true"}]}`), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := spec.Files[0].ExcludePatterns; len(got) != 1 || got[0] != "*.tmp" {
+		t.Errorf("Expected the legacy excludePatterns, Got: %q", got)
+	}
+	if got := unknownSpecFields([]byte(`{"pattern": "a", "newOption": "true", "another": 1}`)); strings.Join(got, ",") != "another,newOption" {
+		t.Errorf("Expected the unknown fields, Got: %q", got)
+	}
+}
+
+func TestFileSpecValidate(t *testing.T) {
+	spec := FileSpec{Files: []FileSpecEntry{
+		{Pattern: "a/*", Target: "repo/"},
+		{Target: "repo/", Flat: "yes"},
+		{Pattern: "b/*", Regexp: "true", Ant: "true", Archive: "tar", SortOrder: "up"},
+	}}
+
+	err := spec.Validate(specUpload)
+	want := []string{
+		"files[1]: pattern or aql must be set",
+		`files[1]: flat must be true or false, got "yes"`,
+		"files[2]: missing target",
+		"files[2]: regexp and ant cannot be set together",
+		`files[2]: archive must be zip, got "tar"`,
+		`files[2]: sortOrder must be asc or desc, got "up"`,
+	}
+	specErr, ok := err.(*SpecError)
+	if !ok || !reflect.DeepEqual(specErr.Problems, want) {
+		t.Errorf("Expected: %q, Got: %v", want, err)
+	}
+}
+
+func TestCheckSpecSettings(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specPath, []byte(`{"files": [{"pattern": "repo/*"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := GetRtCommandsList(Args{
+		Command: "download", URL: RtUrlTestStr, AccessToken: RtAccessToken, SpecPath: specPath,
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	_, err = GetRtCommandsList(Args{
+		Command: "download", URL: RtUrlTestStr, AccessToken: RtAccessToken, Spec: `{"files": [{"target": "a/"}]}`,
+	})
+	want := "invalid settings for download: PLUGIN_SPEC files[0]: pattern or aql must be set"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+
+	problems := checkSpec("PLUGIN_SPEC", specPath, "", specUpload, Pipeline{})
+	if len(problems) != 1 || problems[0] != "PLUGIN_SPEC files[0]: missing target" {
+		t.Errorf("Unexpected problems: %q", problems)
+	}
+
+	problems = checkSpec("PLUGIN_SPEC_PATH", filepath.Join(t.TempDir(), "missing.json"), "", specDownload, Pipeline{})
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], "PLUGIN_SPEC_PATH: cannot read spec file") {
+		t.Errorf("Unexpected problems: %q", problems)
+	}
+}
+
+func TestLoadSpecYAML(t *testing.T) {
+	inline := `files:
+  - pattern: dist/*.zip
+    target: repo/app/
+    flat: true
+    exclusions: ["*.tmp"]
+`
+	data, err := loadSpec(inline, Pipeline{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	spec, err := ParseFileSpec(data, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := FileSpecEntry{Pattern: "dist/*.zip", Target: "repo/app/", Flat: "true", Exclusions: []string{"*.tmp"}}
+	if !reflect.DeepEqual(spec.Files[0], want) {
+		t.Errorf("Expected: %+v, Got: %+v", want, spec.Files[0])
+	}
+
+	specPath := filepath.Join(t.TempDir(), "spec.yml")
+	if err := os.WriteFile(specPath, []byte(inline), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := loadSpec(specPath, Pipeline{})
+	if err != nil || string(fromFile) != string(data) {
+		t.Errorf("Expected: %s, Got: %s, %v", data, fromFile, err)
+	}
+
+	if _, err := loadSpec("files:\n  - pattern: [a\n", Pipeline{}); err == nil || !strings.HasPrefix(err.Error(), "invalid YAML") {
+		t.Errorf("Expected an invalid YAML error, Got: %v", err)
+	}
+}
+
+func TestMaterializeSpec(t *testing.T) {
+	ws := &workspace{}
+	jsonPath := filepath.Join(t.TempDir(), "spec.json")
+
+	path, err := materializeSpec(ws, jsonPath, Pipeline{})
+	if err != nil || path != jsonPath {
+		t.Errorf("Expected JSON spec files to be used as they are, Got: %s, %v", path, err)
+	}
+
+	path, err = materializeSpec(ws, `{"files": [{"pattern": "repo/*"}]}`, Pipeline{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != `{"files": [{"pattern": "repo/*"}]}` {
+		t.Errorf("Unexpected spec file: %s, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("Expected a private workspace, Got: %v, %v", info, err)
+	}
+
+	if err := ws.cleanup(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("Expected the workspace to be removed, Got: %v", err)
+	}
+
+	if _, err := materializeSpec(nil, "files:\n  - pattern: a\n", Pipeline{}); err == nil {
+		t.Errorf("Expected an error without a workspace")
+	}
+}
+
+func TestDryRunUploadInlineSpec(t *testing.T) {
+	args := Args{
+		DryRun:      true,
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		Spec:        "files:\n  - pattern: dist/*.zip\n    target: repo/app/\n",
+	}
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := strings.Index(out, "--spec=")
+	if start < 0 {
+		t.Fatalf("Expected a spec file, Got: %s", out)
+	}
+	specPath := strings.Fields(out[start+len("--spec="):])[0]
+	if !strings.HasPrefix(filepath.Base(specPath), "spec-") || !strings.HasSuffix(specPath, ".json") {
+		t.Errorf("Unexpected spec file: %s", specPath)
+	}
+	if _, err := os.Stat(specPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the spec file to be removed after the step, Got: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// shortCommitLength is the length of a commit sha shortened with short.
+const shortCommitLength = 8
+
+var templateFuncs = template.FuncMap{
+	"short": func(s string) string {
+		if len(s) > shortCommitLength {
+			return s[:shortCommitLength]
+		}
+		return s
+	},
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"jsonEscape": jsonEscape,
+}
+
+// renderTemplates renders the placeholders of PLUGIN_TARGET and
+// PLUGIN_SPEC_VARS, such as {{ .Commit.Rev | short }}, from the pipeline.
+// Specs are rendered once loaded, see loadSpec.
+func renderTemplates(args Args) (Args, error) {
+	for _, setting := range []struct {
+		name  string
+		value *string
+	}{
+		{"PLUGIN_TARGET", &args.Target},
+		{"PLUGIN_SPEC_VARS", &args.SpecVars},
+	} {
+		rendered, err := renderTemplate(setting.name, *setting.value, args.Pipeline, false)
+		if err != nil {
+			return args, err
+		}
+		*setting.value = rendered
+	}
+	return args, nil
+}
+
+// renderTemplate renders text from the pipeline. With escapeJSON the output
+// of every action is escaped to fit in a JSON string, so a branch name or
+// commit message holding quotes keeps the spec valid.
+func renderTemplate(name, text string, pipeline Pipeline, escapeJSON bool) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s template: %s", name, err)
+	}
+	if escapeJSON {
+		escapeActions(tmpl.Tree, tmpl.Tree.Root)
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, pipeline); err != nil {
+		return "", fmt.Errorf("error rendering %s template: %s", name, err)
+	}
+	return rendered.String(), nil
+}
+
+// escapeActions pipes the output of the actions printing a value to
+// jsonEscape, the way html/template escapes them.
+func escapeActions(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeActions(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		escape := parse.NewIdentifier("jsonEscape").SetTree(tree).SetPos(n.Pos)
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{escape}})
+	case *parse.IfNode:
+		escapeActions(tree, n.List)
+		escapeActions(tree, n.ElseList)
+	case *parse.RangeNode:
+		escapeActions(tree, n.List)
+		escapeActions(tree, n.ElseList)
+	case *parse.WithNode:
+		escapeActions(tree, n.List)
+		escapeActions(tree, n.ElseList)
+	}
+}
+
+// jsonEscape returns the value as the content of a JSON string.
+func jsonEscape(value interface{}) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fmt.Sprint(value)); err != nil {
+		return ""
+	}
+	encoded := strings.TrimSpace(buf.String())
+	return encoded[1 : len(encoded)-1]
+}
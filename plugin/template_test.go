@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplates(t *testing.T) {
+	args := Args{
+		Target:   "repo/{{ .Repo.Name }}/{{ .Semver.Short }}/{{ .Commit.Rev | short }}/",
+		SpecVars: "branch={{ .Commit.Branch | replace \"/\" \"-\" }}",
+	}
+	args.Repo.Name = "hello-world"
+	args.Semver.Short = "1.2.3"
+	args.Commit.Rev = "6d2f1a8c3b9e"
+	args.Commit.Branch = "feature/login"
+	args.Build.Number = 42
+
+	result, err := renderTemplates(args)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := "repo/hello-world/1.2.3/6d2f1a8c/"; result.Target != want {
+		t.Errorf("Expected: %s, Got: %s", want, result.Target)
+	}
+	if want := "branch=feature-login"; result.SpecVars != want {
+		t.Errorf("Expected: %s, Got: %s", want, result.SpecVars)
+	}
+}
+
+func TestLoadSpecTemplates(t *testing.T) {
+	var pipeline Pipeline
+	pipeline.Build.Number = 42
+	pipeline.Commit.Branch = `fix/"quoted" \ path`
+
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "spec.json")
+	specFile := `{"files":[{"pattern":"dist/*","target":"repo/{{ .Build.Number }}/","props":"branch={{ .Commit.Branch }}"}]}`
+	if err := os.WriteFile(specPath, []byte(specFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	yamlPath := filepath.Join(dir, "spec.yaml")
+	if err := os.WriteFile(yamlPath, []byte("files:\n  - pattern: dist/*\n    target: repo/{{ .Build.Number }}/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, spec := range []string{specPath, specFile, yamlPath} {
+		data, err := loadSpec(spec, pipeline)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		fileSpec, err := ParseFileSpec(data, "")
+		if err != nil {
+			t.Fatalf("Expected a valid spec, Got: %v\n%s", err, data)
+		}
+		if entry := fileSpec.Files[0]; entry.Target != "repo/42/" {
+			t.Errorf("Expected the target to be rendered, Got: %s", entry.Target)
+		}
+		if entry := fileSpec.Files[0]; spec != yamlPath && entry.Props != "branch="+pipeline.Commit.Branch {
+			t.Errorf("Expected the branch to be escaped, Got: %s", entry.Props)
+		}
+	}
+
+	ws := &workspace{}
+	defer ws.cleanup()
+	path, err := materializeSpec(ws, specPath, pipeline)
+	if err != nil || path == specPath {
+		t.Errorf("Expected a spec file with templates to be rendered to the workspace, Got: %s, %v", path, err)
+	}
+}
+
+func TestRenderTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		args Args
+		err  string
+	}{
+		{Args{Target: "repo/{{ .Commit.Rev "}, "error parsing PLUGIN_TARGET template"},
+		{Args{SpecVars: "v={{ .Commit.Missing }}"}, "error rendering PLUGIN_SPEC_VARS template"},
+	}
+
+	for _, tc := range tests {
+		_, err := renderTemplates(tc.args)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+			t.Errorf("Expected error starting with %q, Got: %v", tc.err, err)
+		}
+	}
+}
+
+func TestDryRunDownloadTemplate(t *testing.T) {
+	args := Args{
+		DryRun:      true,
+		Command:     "download",
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		Source:      "repo/*.zip",
+		Target:      "dist/{{ .Semver.Version }}/",
+	}
+	args.Semver.Version = "1.2.3"
+
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "dist/1.2.3/"; !strings.Contains(out, want) {
+		t.Errorf("Expected: %s, Got: %s", want, out)
+	}
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// checkVerify returns the problems of PLUGIN_VERIFY_CHECKSUMS and
+// PLUGIN_MANIFEST_FILE.
+func checkVerify(args Args) []string {
+	if !args.VerifyChecksums {
+		if args.ManifestFile != "" {
+			return []string{"PLUGIN_MANIFEST_FILE requires PLUGIN_VERIFY_CHECKSUMS"}
+		}
+		return nil
+	}
+	var problems []string
+	if args.DetailedSummary != "" && !parseBoolOrDefault(false, args.DetailedSummary) {
+		problems = append(problems, "PLUGIN_VERIFY_CHECKSUMS requires PLUGIN_DETAILED_SUMMARY")
+	}
+	if parseBoolOrDefault(false, args.Explode) || args.Archive != "" {
+		problems = append(problems, "PLUGIN_VERIFY_CHECKSUMS cannot be used with PLUGIN_EXPLODE or PLUGIN_ARCHIVE, "+
+			"the stored files differ from the local ones")
+	}
+	return problems
+}
+
+// verifyUpload compares the SHA-256 of every uploaded local file with the
+// checksum Artifactory stored for it, and writes the verified checksums to
+// PLUGIN_MANIFEST_FILE.
+func verifyUpload(ctx context.Context, args Args, artifacts []ArtifactDetails) error {
+	if len(artifacts) == 0 {
+		if args.DryRun {
+			return nil
+		}
+		return fmt.Errorf("checksum verification failed: no uploaded files reported")
+	}
+
+	client, err := newRestClient(args)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	verified := make([]ArtifactDetails, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		remotePath := remoteItemPath(args.URL, artifact.RemotePath)
+		local, err := fileChecksums(artifact.LocalPath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", remotePath, err))
+			continue
+		}
+		remote, err := client.storageSha256(ctx, remotePath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", remotePath, err))
+			continue
+		}
+		if args.DryRun {
+			continue
+		}
+		if remote != local.Sha256 {
+			problems = append(problems, fmt.Sprintf("%s: local sha256 %s, remote sha256 %s", remotePath, local.Sha256, remote))
+			continue
+		}
+		verified = append(verified, ArtifactDetails{LocalPath: artifact.LocalPath, RemotePath: remotePath, Sha256: remote})
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("checksum verification failed: %s", strings.Join(problems, "; "))
+	}
+	if args.DryRun {
+		return nil
+	}
+	logrus.Printf("Verified the sha256 of %d uploaded files\n", len(verified))
+
+	if args.ManifestFile != "" {
+		if err := writeManifest(args.ManifestFile, verified); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storageSha256 returns the SHA-256 Artifactory stored for the item, read
+// from the storage API.
+func (c *restClient) storageSha256(ctx context.Context, itemPath string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.baseURL+"/api/storage/"+escapeItemPath(itemPath), nil)
+	if err != nil {
+		return "", err
+	}
+	var info struct {
+		Checksums struct {
+			Sha256 string `json:"sha256"`
+		} `json:"checksums"`
+	}
+	if err := c.do(req, &info); err != nil {
+		return "", fmt.Errorf("error reading storage info: %s", err)
+	}
+	if info.Checksums.Sha256 == "" && !c.dryRun {
+		return "", fmt.Errorf("storage info has no sha256")
+	}
+	return info.Checksums.Sha256, nil
+}
+
+// remoteItemPath returns the repository path of an uploaded file, jf reports
+// either the path or the full URL of the item.
+func remoteItemPath(baseURL, target string) string {
+	if !strings.Contains(target, "://") {
+		return strings.TrimPrefix(target, "/")
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	itemPath := u.Path
+	if base, err := url.Parse(baseURL); err == nil {
+		itemPath = strings.TrimPrefix(itemPath, strings.TrimSuffix(base.Path, "/"))
+	}
+	return strings.TrimPrefix(itemPath, "/")
+}
+
+// serverManifestFile returns the manifest file of a server of
+// PLUGIN_SERVERS, named after it: dist/release.sha256 becomes
+// dist/release-eu.sha256.
+func serverManifestFile(path, name string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + name + ext
+}
+
+// writeManifest writes the verified checksums in the sha256sum format, one
+// "<sha256>  <repository path>" line per file.
+func writeManifest(path string, artifacts []ArtifactDetails) error {
+	var manifest strings.Builder
+	for _, artifact := range artifacts {
+		fmt.Fprintf(&manifest, "%s  %s\n", artifact.Sha256, artifact.RemotePath)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creating manifest folder: %s", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(manifest.String()), 0644); err != nil {
+		return fmt.Errorf("error writing manifest file: %s", err)
+	}
+	return nil
+}
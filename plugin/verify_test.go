@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// helloSha256 is the sha256 of "hello".
+const helloSha256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func newStorageServer(t *testing.T, sha256 string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/artifactory/api/storage/libs-release/app.txt":
+			fmt.Fprintf(w, `{"repo":"libs-release","path":"/app.txt","checksums":{"sha256":%q}}`, sha256)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVerifyUploadNative(t *testing.T) {
+	server := newStorageServer(t, helloSha256)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := Args{
+		Client:          ClientNative,
+		URL:             server.URL + "/artifactory/",
+		AccessToken:     RtAccessToken,
+		Source:          file,
+		Target:          "libs-release/",
+		Flat:            "true",
+		VerifyChecksums: true,
+		ManifestFile:    filepath.Join(dir, "out", "manifest.sha256"),
+	}
+	if err := Exec(context.Background(), args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	manifest, err := os.ReadFile(args.ManifestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := helloSha256 + "  libs-release/app.txt\n"; string(manifest) != want {
+		t.Errorf("Expected: %q, Got: %q", want, manifest)
+	}
+}
+
+func TestVerifyUploadMismatch(t *testing.T) {
+	server := newStorageServer(t, "0000")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := Args{URL: server.URL + "/artifactory", AccessToken: RtAccessToken, ManifestFile: filepath.Join(dir, "manifest")}
+	err := verifyUpload(context.Background(), args, []ArtifactDetails{
+		{LocalPath: file, RemotePath: server.URL + "/artifactory/libs-release/app.txt"},
+		{LocalPath: file, RemotePath: "libs-release/missing.txt"},
+	})
+	want := "checksum verification failed: libs-release/app.txt: local sha256 " + helloSha256 +
+		", remote sha256 0000; libs-release/missing.txt: error reading storage info: unexpected status 404 Not Found"
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Expected: %s, Got: %v", want, err)
+	}
+	if _, err := os.Stat(args.ManifestFile); !os.IsNotExist(err) {
+		t.Errorf("Expected no manifest on failure")
+	}
+}
+
+func TestServerManifestFile(t *testing.T) {
+	server := Server{Name: "eu", URL: "https://eu.test.io/artifactory/"}
+	if got := server.apply(Args{ManifestFile: "dist/release.sha256"}).ManifestFile; got != "dist/release-eu.sha256" {
+		t.Errorf("Expected: dist/release-eu.sha256, Got: %s", got)
+	}
+	if got := server.apply(Args{}).ManifestFile; got != "" {
+		t.Errorf("Expected no manifest, Got: %s", got)
+	}
+	if got := serverManifestFile("manifest", "us"); got != "manifest-us" {
+		t.Errorf("Expected: manifest-us, Got: %s", got)
+	}
+}
+
+func TestVerifyUploadJf(t *testing.T) {
+	server := newStorageServer(t, helloSha256)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fakeJf := `#!/bin/sh
+case "$1 $2" in
+"rt u")
+  echo '{"status":"success","files":[{"source":"` + file + `","target":"` + server.URL +
+		`/artifactory/libs-release/app.txt"}]}' ;;
+esac
+`
+	installFakeJf(t, fakeJf)
+
+	args := Args{
+		URL:             server.URL + "/artifactory/",
+		AccessToken:     RtAccessToken,
+		Source:          file,
+		Target:          "libs-release/",
+		VerifyChecksums: true,
+	}
+	out, err := captureStdout(t, func() error { return Exec(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "--detailed-summary=true") {
+		t.Errorf("Expected the detailed summary to be enabled, Got: %s", out)
+	}
+}
+
+func TestCheckVerify(t *testing.T) {
+	tests := []struct {
+		args Args
+		want string
+	}{
+		{Args{VerifyChecksums: true, ManifestFile: "manifest"}, ""},
+		{Args{ManifestFile: "manifest"}, "PLUGIN_MANIFEST_FILE requires PLUGIN_VERIFY_CHECKSUMS"},
+		{Args{VerifyChecksums: true, DetailedSummary: "false", Archive: "zip"},
+			"PLUGIN_VERIFY_CHECKSUMS requires PLUGIN_DETAILED_SUMMARY; PLUGIN_VERIFY_CHECKSUMS cannot be used " +
+				"with PLUGIN_EXPLODE or PLUGIN_ARCHIVE, the stored files differ from the local ones"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(checkVerify(tt.args), "; "); got != tt.want {
+			t.Errorf("Expected: %s, Got: %s", tt.want, got)
+		}
+	}
+}
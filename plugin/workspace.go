@@ -0,0 +1,163 @@
+package plugin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// workspace is a private temporary folder holding the files generated for
+// a step, such as spec files and the jf home. It is created on first use
+// and removed once the step completes.
+type workspace struct {
+	mu  sync.Mutex
+	dir string
+	// idSuffix makes the server config ids of the step unique.
+	idSuffix string
+	// written lists the files created outside the folder, removed with it.
+	written []string
+}
+
+// ensureDir creates the folder if needed, w.mu must be held.
+func (w *workspace) ensureDir() error {
+	if w.dir != "" {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "drone-artifactory-")
+	if err != nil {
+		return fmt.Errorf("error creating workspace: %s", err)
+	}
+	w.dir = dir
+	return nil
+}
+
+// writeFile writes data to a new file named after pattern, see os.CreateTemp,
+// readable by the current user only.
+func (w *workspace) writeFile(pattern string, data []byte) (string, error) {
+	if w == nil {
+		return "", fmt.Errorf("no workspace to write %s to", pattern)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ensureDir(); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(w.dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("error creating workspace file: %s", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return "", fmt.Errorf("error writing workspace file: %s", err)
+	}
+	return f.Name(), nil
+}
+
+// jfrogHome returns the jf home of the step, holding the server configs and
+// certificates, so that parallel steps do not share or leave them behind.
+func (w *workspace) jfrogHome() (string, error) {
+	if w == nil {
+		return "", fmt.Errorf("no workspace to hold the jf home")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ensureDir(); err != nil {
+		return "", err
+	}
+	home := filepath.Join(w.dir, "jfrog-home")
+	if err := os.MkdirAll(home, 0700); err != nil {
+		return "", fmt.Errorf("error creating jf home: %s", err)
+	}
+	return home, nil
+}
+
+// serverIdSuffix returns the random suffix of the server config ids of the
+// step, generated on first use. Without a workspace the ids are not
+// suffixed.
+func (w *workspace) serverIdSuffix() string {
+	if w == nil {
+		return ""
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.idSuffix == "" {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			return ""
+		}
+		w.idSuffix = hex.EncodeToString(b)
+	}
+	return w.idSuffix
+}
+
+// track records a file written outside the workspace to remove on cleanup.
+func (w *workspace) track(path string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.written = append(w.written, path)
+}
+
+// cleanup removes the workspace and everything written to it.
+func (w *workspace) cleanup() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	for _, path := range w.written {
+		if removeErr := os.Remove(path); removeErr != nil && !os.IsNotExist(removeErr) {
+			err = removeErr
+		}
+	}
+	w.written = nil
+	if w.dir == "" {
+		return err
+	}
+	if removeErr := os.RemoveAll(w.dir); removeErr != nil {
+		err = removeErr
+	}
+	w.dir = ""
+	return err
+}
+
+// sharedJfrogHome returns the jf home of the runner, JFROG_CLI_HOME_DIR or
+// ~/.jfrog.
+func sharedJfrogHome() string {
+	if home := os.Getenv("JFROG_CLI_HOME_DIR"); home != "" {
+		return home
+	}
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(userHome, ".jfrog")
+}
+
+// jfrogHomeEnv returns the environment pointing jf to the home of the step.
+// The downloaded build tool extractors stay in the shared home, they hold
+// no credentials and are costly to fetch again.
+func jfrogHomeEnv(args Args) ([]string, error) {
+	if args.workspace == nil {
+		return nil, nil
+	}
+	home, err := args.workspace.jfrogHome()
+	if err != nil {
+		return nil, err
+	}
+	env := []string{"JFROG_CLI_HOME_DIR=" + home}
+	if os.Getenv("JFROG_CLI_DEPENDENCIES_DIR") == "" {
+		if shared := sharedJfrogHome(); shared != "" {
+			env = append(env, "JFROG_CLI_DEPENDENCIES_DIR="+filepath.Join(shared, "dependencies"))
+		}
+	}
+	return env, nil
+}
@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrivateJfrogHome(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	fakeJf := `#!/bin/sh
+echo "$JFROG_CLI_HOME_DIR" >> ` + calls + `
+[ -f "$JFROG_CLI_HOME_DIR/security/certs/cert.pem" ] && echo pem >> ` + calls + `
+[ "$1" = "config" ] && touch "$JFROG_CLI_HOME_DIR/jfrog-cli.conf.v6"
+exit 0
+`
+	installFakeJf(t, fakeJf)
+	t.Setenv("JFROG_CLI_HOME_DIR", filepath.Join(dir, "shared"))
+
+	args := Args{
+		AccessToken:     RtAccessToken,
+		URL:             RtUrlTestStr,
+		Command:         "promote",
+		Target:          "release-repo",
+		BuildName:       RtBuildName,
+		BuildNumber:     RtBuildNumber,
+		PEMFileContents: "-----BEGIN CERTIFICATE-----",
+	}
+	if err := Exec(context.Background(), args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, _ := os.ReadFile(calls)
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	if len(lines) != 4 || lines[0] != lines[2] || lines[1] != "pem" {
+		t.Fatalf("Expected both commands to share a home holding the pem file, Got: %q", lines)
+	}
+	home := lines[0]
+	if home == "" || strings.HasPrefix(home, dir) {
+		t.Errorf("Expected a private jf home, Got: %q", home)
+	}
+	if _, err := os.Stat(home); !os.IsNotExist(err) {
+		t.Errorf("Expected the jf home to be removed, Got: %v", err)
+	}
+}
+
+func TestStepCerts(t *testing.T) {
+	shared := t.TempDir()
+	t.Setenv("JFROG_CLI_HOME_DIR", shared)
+	if err := os.MkdirAll(filepath.Join(shared, "security", "certs"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(shared, "security", "certs", "runner.pem"), []byte("runner"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := &workspace{}
+	pemPath := filepath.Join(t.TempDir(), "certs", "step.pem")
+	args := Args{PEMFileContents: "-----BEGIN CERTIFICATE-----", PEMFilePath: pemPath, workspace: ws}
+	if err := WriteKnownGoodServerCertsForTls(args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	home, err := ws.jfrogHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"runner.pem": "runner",
+		"step.pem":   args.PEMFileContents,
+		"cert.pem":   args.PEMFileContents,
+	} {
+		got, err := os.ReadFile(filepath.Join(home, "security", "certs", name))
+		if err != nil || string(got) != want {
+			t.Errorf("Expected %s in the step's jf home, Got: %q, %v", name, got, err)
+		}
+	}
+
+	if err := ws.cleanup(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(pemPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the written PLUGIN_PEM_FILE_PATH file to be removed, Got: %v", err)
+	}
+}
+
+func TestUserPEMFileKept(t *testing.T) {
+	t.Setenv("JFROG_CLI_HOME_DIR", t.TempDir())
+	ws := &workspace{}
+	pemPath := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(pemPath, []byte("user"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	args := Args{PEMFilePath: pemPath, workspace: ws}
+	if err := WriteKnownGoodServerCertsForTls(args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	home, err := ws.jfrogHome()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(filepath.Join(home, "security", "certs", "cert.pem")); string(got) != "user" {
+		t.Errorf("Expected a copy of the PLUGIN_PEM_FILE_PATH file, Got: %q, %v", got, err)
+	}
+	if err := ws.cleanup(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(pemPath); err != nil {
+		t.Errorf("Expected the PLUGIN_PEM_FILE_PATH file to be kept, Got: %v", err)
+	}
+}
+
+func TestStepServerIdUnique(t *testing.T) {
+	first, second := &workspace{}, &workspace{}
+	args := Args{workspace: first}
+	id := stepServerId(args, "bpi")
+	if !strings.HasPrefix(id, "tmpServerIdbpi-") || id != stepServerId(args, "bpi") {
+		t.Errorf("Expected a stable id for the step, Got: %s, %s", id, stepServerId(args, "bpi"))
+	}
+	if other := stepServerId(Args{workspace: second}, "bpi"); other == id {
+		t.Errorf("Expected steps to get their own ids, Got: %s twice", id)
+	}
+	if got := stepServerId(Args{}, ""); got != tmpServerId {
+		t.Errorf("Expected: %s, Got: %s", tmpServerId, got)
+	}
+}
+
+func TestResolverServerIdDefault(t *testing.T) {
+	args := Args{
+		workspace:   &workspace{},
+		AccessToken: RtAccessToken,
+		URL:         RtUrlTestStr,
+		MvnGoals:    "clean install",
+		GradleTasks: "build",
+	}
+	want := stepServerId(args, "")
+	for name, commands := range map[string]func(Args) ([][]string, error){
+		MvnCmd:    GetMavenBuildCommandArgs,
+		GradleCmd: GetGradleCommandArgs,
+	} {
+		cmdList, err := commands(args)
+		if err != nil {
+			t.Fatalf("%s: Unexpected error: %v", name, err)
+		}
+		if got := cmdList[0][2]; got != want {
+			t.Errorf("%s: Expected the server config %s, Got: %s", name, want, got)
+		}
+	}
+}